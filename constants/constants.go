@@ -27,13 +27,16 @@ const (
 	RemoteFreeformProfile = "remote_freeform"
 	LocalSource           = "local"
 
-	Consistency  = "consistency"
-	DBPreference = "db_preference"
-	SlaveDB      = "slave_db"
+	Consistency   = "consistency"
+	DBPreference  = "db_preference"
+	SlaveDB       = "slave_db"
+	DBTransaction = "db_transaction"
 
 	Authorization = "Authorization"
 	Bearer        = "Bearer"
 	AccessToken   = "access_token"
 
 	UserDetails = "user_details"
+
+	SCDChangeInfo = "scd_change_info"
 )
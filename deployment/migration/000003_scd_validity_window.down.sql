@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS payment_line_items_id_validity_idx;
+DROP INDEX IF EXISTS timelog_id_validity_idx;
+DROP INDEX IF EXISTS job_id_validity_idx;
+
+ALTER TABLE payment_line_items DROP COLUMN IF EXISTS valid_to, DROP COLUMN IF EXISTS valid_from;
+ALTER TABLE timelog DROP COLUMN IF EXISTS valid_to, DROP COLUMN IF EXISTS valid_from;
+ALTER TABLE job DROP COLUMN IF EXISTS valid_to, DROP COLUMN IF EXISTS valid_from;
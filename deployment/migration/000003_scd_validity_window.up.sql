@@ -0,0 +1,31 @@
+BEGIN;
+
+-- Track the period during which each SCD version was the current one, valid_from inclusive and valid_to exclusive.
+-- A replaced version is closed when the version after it starts. Versions written before this migration carry no
+-- timestamp and all start at the migration time, so their history cannot be reconstructed: the earlier versions of
+-- a record get an empty window and as-of reads see only its latest version, from the migration time on. The versions
+-- themselves stay available by version number.
+ALTER TABLE job ADD COLUMN IF NOT EXISTS valid_from TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE job ADD COLUMN IF NOT EXISTS valid_to TIMESTAMP;
+UPDATE job SET valid_to = (
+    SELECT MIN(later.valid_from) FROM job later WHERE later.id = job.id AND later.version > job.version
+) WHERE is_latest = false;
+
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS valid_from TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS valid_to TIMESTAMP;
+UPDATE timelog SET valid_to = (
+    SELECT MIN(later.valid_from) FROM timelog later WHERE later.id = timelog.id AND later.version > timelog.version
+) WHERE is_latest = false;
+
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS valid_from TIMESTAMP NOT NULL DEFAULT now();
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS valid_to TIMESTAMP;
+UPDATE payment_line_items SET valid_to = (
+    SELECT MIN(later.valid_from) FROM payment_line_items later WHERE later.id = payment_line_items.id AND later.version > payment_line_items.version
+) WHERE is_latest = false;
+
+-- Create index on the validity window for point-in-time lookups
+CREATE INDEX IF NOT EXISTS job_id_validity_idx ON job(id, valid_from, valid_to);
+CREATE INDEX IF NOT EXISTS timelog_id_validity_idx ON timelog(id, valid_from, valid_to);
+CREATE INDEX IF NOT EXISTS payment_line_items_id_validity_idx ON payment_line_items(id, valid_from, valid_to);
+
+COMMIT;
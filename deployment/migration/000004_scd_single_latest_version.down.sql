@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS payment_line_items_id_latest_uidx;
+DROP INDEX IF EXISTS timelog_id_latest_uidx;
+DROP INDEX IF EXISTS job_id_latest_uidx;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- At most one version of a record can be flagged as the latest one
+CREATE UNIQUE INDEX IF NOT EXISTS job_id_latest_uidx ON job(id) WHERE is_latest;
+CREATE UNIQUE INDEX IF NOT EXISTS timelog_id_latest_uidx ON timelog(id) WHERE is_latest;
+CREATE UNIQUE INDEX IF NOT EXISTS payment_line_items_id_latest_uidx ON payment_line_items(id) WHERE is_latest;
+
+COMMIT;
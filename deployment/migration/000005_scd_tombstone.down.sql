@@ -0,0 +1,3 @@
+ALTER TABLE payment_line_items DROP COLUMN IF EXISTS is_deleted;
+ALTER TABLE timelog DROP COLUMN IF EXISTS is_deleted;
+ALTER TABLE job DROP COLUMN IF EXISTS is_deleted;
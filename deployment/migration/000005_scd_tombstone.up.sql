@@ -0,0 +1,8 @@
+BEGIN;
+
+-- A deleted record keeps its history, its latest version is a tombstone flagged with is_deleted
+ALTER TABLE job ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS is_deleted BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
@@ -0,0 +1,3 @@
+ALTER TABLE payment_line_items DROP COLUMN IF EXISTS change_reason, DROP COLUMN IF EXISTS changed_by;
+ALTER TABLE timelog DROP COLUMN IF EXISTS change_reason, DROP COLUMN IF EXISTS changed_by;
+ALTER TABLE job DROP COLUMN IF EXISTS change_reason, DROP COLUMN IF EXISTS changed_by;
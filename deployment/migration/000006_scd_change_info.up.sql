@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Who wrote a version and why, e.g. the admin that reverted a record to an earlier version
+ALTER TABLE job ADD COLUMN IF NOT EXISTS changed_by VARCHAR(255);
+ALTER TABLE job ADD COLUMN IF NOT EXISTS change_reason TEXT;
+
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS changed_by VARCHAR(255);
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS change_reason TEXT;
+
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS changed_by VARCHAR(255);
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS change_reason TEXT;
+
+COMMIT;
@@ -0,0 +1,3 @@
+ALTER TABLE payment_line_items DROP COLUMN IF EXISTS fx_rate, DROP COLUMN IF EXISTS payout_currency, DROP COLUMN IF EXISTS currency;
+ALTER TABLE contractor DROP COLUMN IF EXISTS payout_currency;
+ALTER TABLE job DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- ISO-4217 currency of a job's rate and of the line items priced with it
+ALTER TABLE job ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Currency a contractor is paid out in
+ALTER TABLE contractor ADD COLUMN IF NOT EXISTS payout_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Units of payout_currency one unit of currency bought when the line item version was written
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS payout_currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE payment_line_items ADD COLUMN IF NOT EXISTS fx_rate DECIMAL(18, 8) NOT NULL DEFAULT 1;
+
+COMMIT;
@@ -0,0 +1,2 @@
+ALTER TABLE job DROP CONSTRAINT IF EXISTS job_status_check;
+ALTER TABLE payment_line_items DROP CONSTRAINT IF EXISTS payment_line_items_status_check;
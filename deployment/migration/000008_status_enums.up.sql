@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Line items generated before the status enum was introduced used not-paid for pending
+UPDATE payment_line_items SET status = 'pending' WHERE status = 'not-paid';
+
+-- NOT VALID keeps rows written before the enums were introduced, every new version is checked
+ALTER TABLE payment_line_items DROP CONSTRAINT IF EXISTS payment_line_items_status_check;
+ALTER TABLE payment_line_items ADD CONSTRAINT payment_line_items_status_check
+    CHECK (status IN ('pending', 'approved', 'paid', 'voided')) NOT VALID;
+
+ALTER TABLE job DROP CONSTRAINT IF EXISTS job_status_check;
+ALTER TABLE job ADD CONSTRAINT job_status_check
+    CHECK (status IN ('draft', 'active', 'extended', 'paused', 'ended')) NOT VALID;
+
+COMMIT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payout_item;
+DROP TABLE IF EXISTS payout;
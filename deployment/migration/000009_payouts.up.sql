@@ -0,0 +1,37 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS payout (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    contractor_id VARCHAR(255) NOT NULL,
+    period_start BIGINT NOT NULL,
+    period_end BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    total_amount DECIMAL(12, 2) NOT NULL,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('pending', 'settled', 'cancelled')),
+    settled_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS payout_contractor_id_idx ON payout(contractor_id);
+
+-- The line item versions a payout pays, frozen when the payout is created
+CREATE TABLE IF NOT EXISTS payout_item (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    payout_id VARCHAR(255) NOT NULL REFERENCES payout(id),
+    payment_line_item_id VARCHAR(255) NOT NULL,
+    payment_line_item_uid VARCHAR(255) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    fx_rate DECIMAL(18, 8) NOT NULL,
+    payout_amount DECIMAL(12, 2) NOT NULL,
+    released BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS payout_item_payout_id_idx ON payout_item(payout_id);
+
+-- A line item is held by at most one payout, cancelled payouts release their items
+CREATE UNIQUE INDEX IF NOT EXISTS payout_item_payment_line_item_id_unique_idx
+    ON payout_item(payment_line_item_id) WHERE NOT released;
+
+COMMIT;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS invoice_line;
+DROP TABLE IF EXISTS invoice;
+DROP SEQUENCE IF EXISTS invoice_number_seq;
@@ -0,0 +1,41 @@
+BEGIN;
+
+-- Invoice numbers are taken when an invoice is finalized, drafts have none
+CREATE SEQUENCE IF NOT EXISTS invoice_number_seq;
+
+CREATE TABLE IF NOT EXISTS invoice (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    number VARCHAR(50) UNIQUE,
+    company_id VARCHAR(255) NOT NULL,
+    period_start BIGINT NOT NULL,
+    period_end BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    total_amount DECIMAL(12, 2) NOT NULL,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('draft', 'finalized', 'void')),
+    finalized_at TIMESTAMP,
+    voided_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS invoice_company_id_idx ON invoice(company_id);
+
+-- The line item versions an invoice bills, pinned when the invoice is drafted
+CREATE TABLE IF NOT EXISTS invoice_line (
+    id VARCHAR(255) NOT NULL PRIMARY KEY,
+    invoice_id VARCHAR(255) NOT NULL REFERENCES invoice(id),
+    payment_line_item_id VARCHAR(255) NOT NULL,
+    payment_line_item_uid VARCHAR(255) NOT NULL,
+    job_uid VARCHAR(255) NOT NULL,
+    timelog_uid VARCHAR(255) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    released BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX IF NOT EXISTS invoice_line_invoice_id_idx ON invoice_line(invoice_id);
+
+-- A line item is billed by at most one invoice, void invoices release their lines
+CREATE UNIQUE INDEX IF NOT EXISTS invoice_line_payment_line_item_id_unique_idx
+    ON invoice_line(payment_line_item_id) WHERE NOT released;
+
+COMMIT;
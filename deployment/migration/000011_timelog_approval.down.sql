@@ -0,0 +1,4 @@
+ALTER TABLE timelog DROP CONSTRAINT IF EXISTS timelog_approval_status_check;
+ALTER TABLE timelog DROP COLUMN IF EXISTS approval_reason;
+ALTER TABLE timelog DROP COLUMN IF EXISTS approver_id;
+ALTER TABLE timelog DROP COLUMN IF EXISTS approval_status;
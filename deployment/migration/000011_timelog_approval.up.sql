@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Timelogs logged before the approval workflow already have line items, they count as approved
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS approval_status VARCHAR(50) NOT NULL DEFAULT 'approved';
+ALTER TABLE timelog ALTER COLUMN approval_status SET DEFAULT 'pending';
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS approver_id VARCHAR(255);
+ALTER TABLE timelog ADD COLUMN IF NOT EXISTS approval_reason TEXT;
+
+ALTER TABLE timelog DROP CONSTRAINT IF EXISTS timelog_approval_status_check;
+ALTER TABLE timelog ADD CONSTRAINT timelog_approval_status_check
+    CHECK (approval_status IN ('pending', 'approved', 'rejected'));
+
+COMMIT;
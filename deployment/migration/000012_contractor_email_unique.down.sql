@@ -0,0 +1,3 @@
+ALTER TABLE contractor DROP COLUMN IF EXISTS deactivated_at;
+DROP INDEX IF EXISTS contractor_email_unique_idx;
+ALTER TABLE contractor ALTER COLUMN email TYPE VARCHAR(255);
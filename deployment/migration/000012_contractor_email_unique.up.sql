@@ -0,0 +1,10 @@
+BEGIN;
+
+-- Emails compare case-insensitively, duplicates that only differ in case have to be merged before this runs
+ALTER TABLE contractor ALTER COLUMN email TYPE CITEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS contractor_email_unique_idx ON contractor(email) WHERE deleted_at IS NULL;
+
+ALTER TABLE contractor ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP;
+
+COMMIT;
@@ -0,0 +1,228 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/domain"
+	jobreq "github.com/mercor/payment-service/internal/job/request"
+	middlewares "github.com/mercor/payment-service/internal/middleware"
+	"github.com/mercor/payment-service/pkg/apperror"
+)
+
+// Check returns a not found error when the user may not act on the resource with the id.
+// A resource of someone else is reported like a missing one so that callers can't probe which IDs exist.
+type Check func(ctx context.Context, user *middlewares.UserDetails, id string) error
+
+// Authorizer decides whether a user owns a resource, admins own every resource.
+// A contractor user's ID is the contractor ID and a company user's ID is the company ID.
+type Authorizer struct {
+	jobRepo     domain.JobRepositoryInterface
+	timelogRepo domain.TimeLogRepositoryInterface
+	paymentRepo domain.PaymentLineRepository
+	payoutRepo  domain.PayoutRepository
+	invoiceRepo domain.InvoiceRepository
+}
+
+var (
+	authorizer     *Authorizer
+	authorizerOnce sync.Once
+)
+
+func NewAuthorizer(
+	jobRepo domain.JobRepositoryInterface,
+	timelogRepo domain.TimeLogRepositoryInterface,
+	paymentRepo domain.PaymentLineRepository,
+	payoutRepo domain.PayoutRepository,
+	invoiceRepo domain.InvoiceRepository,
+) *Authorizer {
+	authorizerOnce.Do(func() {
+		authorizer = &Authorizer{
+			jobRepo:     jobRepo,
+			timelogRepo: timelogRepo,
+			paymentRepo: paymentRepo,
+			payoutRepo:  payoutRepo,
+			invoiceRepo: invoiceRepo,
+		}
+	})
+	return authorizer
+}
+
+// Param runs check against the path param of the request, it must run after AuthenticateJWT
+func (a *Authorizer) Param(param string, check Check) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userDetails, ok := middlewares.GetUserDetails(c)
+		if !ok {
+			apperror.Abort(c, apperror.Unauthorized("authentication required"))
+			return
+		}
+
+		if err := check(c, userDetails, c.Param(param)); err != nil {
+			apperror.Abort(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// Contractor lets the contractor through
+func (a *Authorizer) Contractor(_ context.Context, user *middlewares.UserDetails, contractorID string) error {
+	if isAdmin(user) || isContractor(user, contractorID) {
+		return nil
+	}
+	return notFound("contractor", contractorID)
+}
+
+// ContractorProfile lets the contractor and the companies it has a job with through
+func (a *Authorizer) ContractorProfile(ctx context.Context, user *middlewares.UserDetails, contractorID string) error {
+	if isAdmin(user) || isContractor(user, contractorID) {
+		return nil
+	}
+	if user.HasRole(middlewares.RoleCompany) {
+		jobs, err := a.jobRepo.FindLatestWithFilter(ctx, map[string]interface{}{"company_id": user.ID, "contractor_id": contractorID})
+		if err != nil {
+			return err
+		}
+		if len(jobs) > 0 {
+			return nil
+		}
+	}
+	return notFound("contractor", contractorID)
+}
+
+// Company lets the company through
+func (a *Authorizer) Company(_ context.Context, user *middlewares.UserDetails, companyID string) error {
+	if isAdmin(user) || isCompany(user, companyID) {
+		return nil
+	}
+	return notFound("company", companyID)
+}
+
+// Job lets the company and the contractor of the job through
+func (a *Authorizer) Job(ctx context.Context, user *middlewares.UserDetails, jobID string) error {
+	if isAdmin(user) {
+		return nil
+	}
+	job, err := a.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return found(err, "job", jobID)
+	}
+	if !ownsJob(user, job) {
+		return notFound("job", jobID)
+	}
+	return nil
+}
+
+// Timelog lets the company and the contractor of the job the timelog is logged against through
+func (a *Authorizer) Timelog(ctx context.Context, user *middlewares.UserDetails, timelogID string) error {
+	if isAdmin(user) {
+		return nil
+	}
+	timelog, err := a.timelogRepo.FindByID(ctx, timelogID)
+	if err != nil {
+		return found(err, "timelog", timelogID)
+	}
+	return a.jobVersion(ctx, user, timelog.JobUID, "timelog", timelogID)
+}
+
+// PaymentLineItem lets the company and the contractor of the job the line item pays for through
+func (a *Authorizer) PaymentLineItem(ctx context.Context, user *middlewares.UserDetails, lineItemID string) error {
+	if isAdmin(user) {
+		return nil
+	}
+	lineItem, err := a.paymentRepo.FindByID(ctx, lineItemID)
+	if err != nil {
+		return found(err, "payment line item", lineItemID)
+	}
+	return a.jobVersion(ctx, user, lineItem.JobUID, "payment line item", lineItemID)
+}
+
+// Payout lets the contractor paid by the payout through
+func (a *Authorizer) Payout(ctx context.Context, user *middlewares.UserDetails, payoutID string) error {
+	if isAdmin(user) {
+		return nil
+	}
+	payout, err := a.payoutRepo.GetByConditions(ctx, map[string]interface{}{"id": payoutID})
+	if err != nil {
+		return found(err, "payout", payoutID)
+	}
+	if !isContractor(user, payout.ContractorID) {
+		return notFound("payout", payoutID)
+	}
+	return nil
+}
+
+// Invoice lets the company billed by the invoice through
+func (a *Authorizer) Invoice(ctx context.Context, user *middlewares.UserDetails, invoiceID string) error {
+	if isAdmin(user) {
+		return nil
+	}
+	invoice, err := a.invoiceRepo.GetByConditions(ctx, map[string]interface{}{"id": invoiceID})
+	if err != nil {
+		return found(err, "invoice", invoiceID)
+	}
+	if !isCompany(user, invoice.CompanyID) {
+		return notFound("invoice", invoiceID)
+	}
+	return nil
+}
+
+// ScopeJobs narrows a job listing to the jobs of the user, filtering on another company or contractor is refused
+func (a *Authorizer) ScopeJobs(user *middlewares.UserDetails, req *jobreq.ListJobsSvcReq) error {
+	if isAdmin(user) {
+		return nil
+	}
+	if user.HasRole(middlewares.RoleCompany) && (req.CompanyID == "" || req.CompanyID == user.ID) {
+		req.CompanyID = user.ID
+		return nil
+	}
+	if user.HasRole(middlewares.RoleContractor) && (req.ContractorID == "" || req.ContractorID == user.ID) {
+		req.ContractorID = user.ID
+		return nil
+	}
+	return apperror.Forbidden("jobs can only be listed for your own company or contractor")
+}
+
+// jobVersion checks the job of a version uid for the resource logged against it,
+// the parties of a job are the same in every version
+func (a *Authorizer) jobVersion(ctx context.Context, user *middlewares.UserDetails, jobUID, resource, id string) error {
+	job, err := a.jobRepo.FindByUID(ctx, jobUID)
+	if err != nil {
+		return found(err, resource, id)
+	}
+	if !ownsJob(user, job) {
+		return notFound(resource, id)
+	}
+	return nil
+}
+
+// notFound is the error of a resource that is missing or belongs to someone else
+func notFound(resource, id string) error {
+	return apperror.NotFound(fmt.Sprintf("%s %s does not exist", resource, id))
+}
+
+// found turns the not found error of a lookup into the error of the resource, so it reads like notFound
+func found(err error, resource, id string) error {
+	if apperror.HasCode(err, apperror.CodeNotFound) {
+		return notFound(resource, id)
+	}
+	return err
+}
+
+func ownsJob(user *middlewares.UserDetails, job *domain.Job) bool {
+	return isCompany(user, job.CompanyID) || isContractor(user, job.ContractorID)
+}
+
+func isAdmin(user *middlewares.UserDetails) bool {
+	return user.HasRole(middlewares.RoleAdmin)
+}
+
+func isCompany(user *middlewares.UserDetails, companyID string) bool {
+	return user.HasRole(middlewares.RoleCompany) && user.ID == companyID
+}
+
+func isContractor(user *middlewares.UserDetails, contractorID string) bool {
+	return user.HasRole(middlewares.RoleContractor) && user.ID == contractorID
+}
@@ -0,0 +1,306 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/constants"
+	"github.com/mercor/payment-service/internal/domain"
+	jobreq "github.com/mercor/payment-service/internal/job/request"
+	middlewares "github.com/mercor/payment-service/internal/middleware"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/mercor/payment-service/pkg/repository/static"
+	"github.com/stretchr/testify/assert"
+)
+
+// The stub repositories hold one job of company cmp-1 and contractor ctr-1 and one record of each kind that belongs to it
+
+type stubJobRepo struct {
+	domain.JobRepositoryInterface
+}
+
+func ownedJob() *domain.Job {
+	job := domain.NewJob(domain.JobStatusActive, money.NewFromInt(10), "USD", "Engineer", "cmp-1", "ctr-1")
+	job.SCDModel = &scd.SCDModel{ID: "job-1", Version: 2, UID: "job-1-v2", IsLatest: true}
+	return job
+}
+
+func (stubJobRepo) FindByID(_ context.Context, id string) (*domain.Job, error) {
+	if id != "job-1" {
+		return nil, scd.ErrRecordNotFound
+	}
+	return ownedJob(), nil
+}
+
+func (stubJobRepo) FindByUID(_ context.Context, uid string) (*domain.Job, error) {
+	if uid != "job-1-v1" && uid != "job-1-v2" {
+		return nil, scd.ErrRecordNotFound
+	}
+	return ownedJob(), nil
+}
+
+func (stubJobRepo) FindLatestWithFilter(_ context.Context, filter map[string]interface{}) ([]domain.Job, error) {
+	job := ownedJob()
+	if filter["company_id"] != job.CompanyID || filter["contractor_id"] != job.ContractorID {
+		return nil, nil
+	}
+	return []domain.Job{*job}, nil
+}
+
+type stubTimelogRepo struct {
+	domain.TimeLogRepositoryInterface
+}
+
+func (stubTimelogRepo) FindByID(_ context.Context, id string) (*domain.Timelog, error) {
+	if id != "timelog-1" {
+		return nil, scd.ErrRecordNotFound
+	}
+	// Logged against an earlier version of the job
+	return domain.NewTimelog(1000, 0, 1000, "captured", "job-1-v1"), nil
+}
+
+type stubPaymentRepo struct {
+	domain.PaymentLineRepository
+}
+
+func (stubPaymentRepo) FindByID(_ context.Context, id string) (*domain.PaymentLineItem, error) {
+	if id != "item-1" {
+		return nil, scd.ErrRecordNotFound
+	}
+	return domain.NewPaymentLineItem("job-1-v2", "timelog-1-v1", money.NewFromInt(10), "USD", "USD", money.NewFromInt(1), domain.PaymentLineItemStatusPending), nil
+}
+
+type stubPayoutRepo struct {
+	domain.PayoutRepository
+}
+
+func (stubPayoutRepo) GetByConditions(_ context.Context, conditions map[string]interface{}) (*domain.Payout, error) {
+	if conditions["id"] != "payout-1" {
+		return nil, static.ErrRecordNotFound
+	}
+	return domain.NewPayout("ctr-1", 0, 1000, "USD", nil)
+}
+
+type stubInvoiceRepo struct {
+	domain.InvoiceRepository
+}
+
+func (stubInvoiceRepo) GetByConditions(_ context.Context, conditions map[string]interface{}) (*domain.Invoice, error) {
+	if conditions["id"] != "invoice-1" {
+		return nil, static.ErrRecordNotFound
+	}
+	return domain.NewInvoice("cmp-1", 0, 1000, "USD", nil)
+}
+
+func assertCode(t *testing.T, want apperror.Code, err error) {
+	var appErr *apperror.Error
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, want, appErr.Code)
+	}
+}
+
+func assertForbidden(t *testing.T, err error) {
+	assertCode(t, apperror.CodeForbidden, err)
+}
+
+// assertNotFound checks that err reports the resource as missing, whether it is missing or someone else's
+func assertNotFound(t *testing.T, resource, id string, err error) {
+	var appErr *apperror.Error
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, apperror.CodeNotFound, appErr.Code)
+		assert.Equal(t, resource+" "+id+" does not exist", appErr.Message)
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	a := &Authorizer{}
+	ctx := context.Background()
+	admin := &middlewares.UserDetails{ID: "root", Roles: []middlewares.Role{middlewares.RoleAdmin}}
+	contractor := &middlewares.UserDetails{ID: "ctr-1", Roles: []middlewares.Role{middlewares.RoleContractor}}
+	company := &middlewares.UserDetails{ID: "cmp-1", Roles: []middlewares.Role{middlewares.RoleCompany}}
+
+	t.Run("should let a contractor through to its own data", func(t *testing.T) {
+		assert.NoError(t, a.Contractor(ctx, contractor, "ctr-1"))
+	})
+
+	t.Run("should not find another contractor for a contractor", func(t *testing.T) {
+		assertNotFound(t, "contractor", "ctr-2", a.Contractor(ctx, contractor, "ctr-2"))
+	})
+
+	t.Run("should not take a company for the contractor with the same ID", func(t *testing.T) {
+		user := &middlewares.UserDetails{ID: "ctr-1", Roles: []middlewares.Role{middlewares.RoleCompany}}
+
+		assertNotFound(t, "contractor", "ctr-1", a.Contractor(ctx, user, "ctr-1"))
+	})
+
+	t.Run("should not find another company for a company", func(t *testing.T) {
+		assertNotFound(t, "company", "cmp-2", a.Company(ctx, company, "cmp-2"))
+	})
+
+	t.Run("should let admins through without looking resources up", func(t *testing.T) {
+		for _, check := range []Check{a.Contractor, a.ContractorProfile, a.Company, a.Job, a.Timelog, a.PaymentLineItem, a.Payout, a.Invoice} {
+			assert.NoError(t, check(ctx, admin, "any"))
+		}
+	})
+
+	t.Run("should scope a company's job listing to the company", func(t *testing.T) {
+		req := &jobreq.ListJobsSvcReq{ContractorID: "ctr-9"}
+
+		assert.NoError(t, a.ScopeJobs(company, req))
+		assert.Equal(t, "cmp-1", req.CompanyID)
+	})
+
+	t.Run("should scope a contractor's job listing to the contractor", func(t *testing.T) {
+		req := &jobreq.ListJobsSvcReq{}
+
+		assert.NoError(t, a.ScopeJobs(contractor, req))
+		assert.Equal(t, "ctr-1", req.ContractorID)
+	})
+
+	t.Run("should refuse listing the jobs of another contractor", func(t *testing.T) {
+		assertForbidden(t, a.ScopeJobs(contractor, &jobreq.ListJobsSvcReq{ContractorID: "ctr-2"}))
+	})
+}
+
+func TestAuthorizerResourceOwnership(t *testing.T) {
+	a := &Authorizer{
+		jobRepo:     stubJobRepo{},
+		timelogRepo: stubTimelogRepo{},
+		paymentRepo: stubPaymentRepo{},
+		payoutRepo:  stubPayoutRepo{},
+		invoiceRepo: stubInvoiceRepo{},
+	}
+	ctx := context.Background()
+	owningContractor := &middlewares.UserDetails{ID: "ctr-1", Roles: []middlewares.Role{middlewares.RoleContractor}}
+	otherContractor := &middlewares.UserDetails{ID: "ctr-2", Roles: []middlewares.Role{middlewares.RoleContractor}}
+	owningCompany := &middlewares.UserDetails{ID: "cmp-1", Roles: []middlewares.Role{middlewares.RoleCompany}}
+	otherCompany := &middlewares.UserDetails{ID: "cmp-2", Roles: []middlewares.Role{middlewares.RoleCompany}}
+
+	tests := []struct {
+		name     string
+		check    Check
+		id       string
+		allowed  []*middlewares.UserDetails
+		refused  []*middlewares.UserDetails
+		notFound string
+	}{
+		{
+			name:     "job",
+			check:    a.Job,
+			id:       "job-1",
+			allowed:  []*middlewares.UserDetails{owningContractor, owningCompany},
+			refused:  []*middlewares.UserDetails{otherContractor, otherCompany},
+			notFound: "job-2",
+		},
+		{
+			name:     "timelog",
+			check:    a.Timelog,
+			id:       "timelog-1",
+			allowed:  []*middlewares.UserDetails{owningContractor, owningCompany},
+			refused:  []*middlewares.UserDetails{otherContractor, otherCompany},
+			notFound: "timelog-2",
+		},
+		{
+			name:     "payment line item",
+			check:    a.PaymentLineItem,
+			id:       "item-1",
+			allowed:  []*middlewares.UserDetails{owningContractor, owningCompany},
+			refused:  []*middlewares.UserDetails{otherContractor, otherCompany},
+			notFound: "item-2",
+		},
+		{
+			name:     "payout",
+			check:    a.Payout,
+			id:       "payout-1",
+			allowed:  []*middlewares.UserDetails{owningContractor},
+			refused:  []*middlewares.UserDetails{otherContractor, owningCompany},
+			notFound: "payout-2",
+		},
+		{
+			name:     "invoice",
+			check:    a.Invoice,
+			id:       "invoice-1",
+			allowed:  []*middlewares.UserDetails{owningCompany},
+			refused:  []*middlewares.UserDetails{otherCompany, owningContractor},
+			notFound: "invoice-2",
+		},
+		{
+			name:    "contractor",
+			check:   a.ContractorProfile,
+			id:      "ctr-1",
+			allowed: []*middlewares.UserDetails{owningContractor, owningCompany},
+			refused: []*middlewares.UserDetails{otherContractor, otherCompany},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("should let the owners of the "+tt.name+" through", func(t *testing.T) {
+			for _, user := range tt.allowed {
+				assert.NoError(t, tt.check(ctx, user, tt.id), user.ID)
+			}
+		})
+
+		t.Run("should not find the "+tt.name+" for others", func(t *testing.T) {
+			for _, user := range tt.refused {
+				assertNotFound(t, tt.name, tt.id, tt.check(ctx, user, tt.id))
+			}
+		})
+
+		if tt.notFound != "" {
+			t.Run("should not find a missing "+tt.name+" like one of someone else", func(t *testing.T) {
+				assertNotFound(t, tt.name, tt.notFound, tt.check(ctx, owningContractor, tt.notFound))
+			})
+		}
+	}
+}
+
+func TestParam(t *testing.T) {
+	a := &Authorizer{jobRepo: stubJobRepo{}}
+	owner := &middlewares.UserDetails{ID: "ctr-1", Roles: []middlewares.Role{middlewares.RoleContractor}}
+	other := &middlewares.UserDetails{ID: "ctr-2", Roles: []middlewares.Role{middlewares.RoleContractor}}
+
+	tests := []struct {
+		name        string
+		user        *middlewares.UserDetails
+		path        string
+		wantCode    int
+		wantMessage string
+	}{
+		{name: "should let the owner of the job through", user: owner, path: "/jobs/job-1", wantCode: http.StatusOK},
+		{name: "should answer not found for the job of someone else", user: other, path: "/jobs/job-1", wantCode: http.StatusNotFound, wantMessage: "job job-1 does not exist"},
+		{name: "should answer not found for a missing job", user: other, path: "/jobs/job-2", wantCode: http.StatusNotFound, wantMessage: "job job-2 does not exist"},
+		{name: "should refuse a request without user details", path: "/jobs/job-1", wantCode: http.StatusUnauthorized, wantMessage: "authentication required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			engine := gin.New()
+			engine.Use(apperror.Middleware())
+			engine.Use(func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set(constants.UserDetails, tt.user)
+				}
+			})
+			engine.GET("/jobs/:job_id", a.Param("job_id", a.Job), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantCode, recorder.Code)
+			if tt.wantMessage != "" {
+				var response apperror.Response
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantMessage, response.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package authorization
+
+import (
+	"github.com/google/wire"
+	invoiceRepository "github.com/mercor/payment-service/internal/invoice/repository"
+	jobRepository "github.com/mercor/payment-service/internal/job/repository"
+	paymentRepository "github.com/mercor/payment-service/internal/payment/repository"
+	payoutRepository "github.com/mercor/payment-service/internal/payout/repository"
+	timelogRepository "github.com/mercor/payment-service/internal/timelog/repository"
+)
+
+var ProviderSet wire.ProviderSet = wire.NewSet(
+	NewAuthorizer,
+	jobRepository.NewJobRepository,
+	timelogRepository.NewTimelogRepository,
+	paymentRepository.NewPaymentRepository,
+	payoutRepository.NewPayoutRepository,
+	invoiceRepository.NewInvoiceRepository,
+)
@@ -0,0 +1,9 @@
+package request
+
+type CreateCompanySvcReq struct {
+	Name string
+}
+
+type UpdateCompanySvcReq struct {
+	Name string
+}
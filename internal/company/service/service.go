@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mercor/payment-service/internal/company/request"
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/static"
+)
+
+type CompanyService struct {
+	repo    domain.CompanyRepository
+	jobRepo domain.JobRepositoryInterface
+	tx      postgres.Transactor
+}
+
+func NewCompanyService(repo domain.CompanyRepository, jobRepo domain.JobRepositoryInterface, tx postgres.Transactor) *CompanyService {
+	return &CompanyService{repo: repo, jobRepo: jobRepo, tx: tx}
+}
+
+func (s *CompanyService) CreateCompany(ctx context.Context, req *request.CreateCompanySvcReq) (*domain.Company, error) {
+	company := domain.NewCompany(req.Name)
+	if err := s.repo.Create(ctx, company); err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// GetCompanyByID returns the company unless it was deleted
+func (s *CompanyService) GetCompanyByID(ctx context.Context, id string) (*domain.Company, error) {
+	company, err := s.repo.GetByConditions(ctx, map[string]interface{}{"id": id})
+	if errors.Is(err, static.ErrRecordNotFound) {
+		return nil, apperror.NotFound(fmt.Sprintf("company %s does not exist", id))
+	}
+	return company, err
+}
+
+func (s *CompanyService) GetCompanies(ctx context.Context, page pagination.Request) (*pagination.Page[domain.Company], error) {
+	return s.repo.GetAllByConditionsPage(ctx, map[string]interface{}{}, page)
+}
+
+func (s *CompanyService) UpdateCompany(ctx context.Context, id string, req *request.UpdateCompanySvcReq) (*domain.Company, error) {
+	var company *domain.Company
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := s.GetCompanyByID(ctx, id); err != nil {
+			return err
+		}
+
+		err := s.repo.UpdatesByConditions(ctx, map[string]interface{}{"id": id}, map[string]interface{}{
+			"name":       req.Name,
+			"updated_at": time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		company, err = s.GetCompanyByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return company, nil
+}
+
+// DeleteCompany soft deletes the company, which is refused while any of its jobs is active
+func (s *CompanyService) DeleteCompany(ctx context.Context, id string) error {
+	return s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := s.GetCompanyByID(ctx, id); err != nil {
+			return err
+		}
+
+		activeJobs, err := s.jobRepo.FindLatestWithFilter(ctx, map[string]interface{}{
+			"company_id": id,
+			"status":     domain.ActiveJobStatusFilter(),
+		})
+		if err != nil {
+			return err
+		}
+		if len(activeJobs) > 0 {
+			return apperror.Conflict(fmt.Sprintf("company %s has %d active jobs, end them before deleting the company", id, len(activeJobs)))
+		}
+
+		return s.repo.DeleteByConditions(ctx, map[string]interface{}{"id": id})
+	})
+}
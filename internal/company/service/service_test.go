@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/static"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubCompanyRepo holds company-1 until it is deleted
+type stubCompanyRepo struct {
+	domain.CompanyRepository
+	deleted bool
+}
+
+func (r *stubCompanyRepo) GetByConditions(_ context.Context, conditions map[string]interface{}) (*domain.Company, error) {
+	if r.deleted || conditions["id"] != "company-1" {
+		return nil, static.ErrRecordNotFound
+	}
+	return &domain.Company{Model: &static.Model{ID: "company-1"}, Name: "Acme"}, nil
+}
+
+func (r *stubCompanyRepo) DeleteByConditions(context.Context, map[string]interface{}) error {
+	r.deleted = true
+	return nil
+}
+
+// stubJobRepo returns its jobs whose status is in the filtered statuses
+type stubJobRepo struct {
+	domain.JobRepositoryInterface
+	jobs []domain.Job
+}
+
+func (r stubJobRepo) FindLatestWithFilter(_ context.Context, filter map[string]interface{}) ([]domain.Job, error) {
+	var jobs []domain.Job
+	for _, job := range r.jobs {
+		for _, status := range filter["status"].([]string) {
+			if string(job.Status) == status {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func TestDeleteCompany(t *testing.T) {
+	job := func(status domain.JobStatus) domain.Job {
+		return *domain.NewJob(status, money.NewFromInt(10), "USD", "Engineer", "company-1", "contractor-1")
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		jobs     []domain.Job
+		wantCode apperror.Code
+	}{
+		{
+			name: "should delete a company without jobs",
+			id:   "company-1",
+		},
+		{
+			name: "should delete a company whose jobs all ended",
+			id:   "company-1",
+			jobs: []domain.Job{job(domain.JobStatusEnded), job(domain.JobStatusEnded)},
+		},
+		{
+			name:     "should refuse to delete a company with an active job",
+			id:       "company-1",
+			jobs:     []domain.Job{job(domain.JobStatusEnded), job(domain.JobStatusActive)},
+			wantCode: apperror.CodeConflict,
+		},
+		{
+			name:     "should refuse to delete a company with an extended job",
+			id:       "company-1",
+			jobs:     []domain.Job{job(domain.JobStatusExtended)},
+			wantCode: apperror.CodeConflict,
+		},
+		{
+			name: "should delete a company whose only job is paused",
+			id:   "company-1",
+			jobs: []domain.Job{job(domain.JobStatusPaused)},
+		},
+		{
+			name:     "should not find a missing company",
+			id:       "company-2",
+			wantCode: apperror.CodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubCompanyRepo{}
+			svc := NewCompanyService(repo, stubJobRepo{jobs: tt.jobs}, postgrestest.Transactor{})
+
+			err := svc.DeleteCompany(context.Background(), tt.id)
+
+			if tt.wantCode != "" {
+				assert.True(t, apperror.HasCode(err, tt.wantCode), err)
+				assert.False(t, repo.deleted)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, repo.deleted)
+		})
+	}
+}
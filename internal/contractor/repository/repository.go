@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/mercor/payment-service/internal/domain"
@@ -28,3 +31,13 @@ func NewContractorRepository(db *postgres.DbCluster) domain.ContractorRepository
 
 	return repo
 }
+
+// LockEmail serializes the writes that claim the email until the surrounding transaction ends,
+// emails are case-insensitive so the lock is too
+func (r *ContractorRepository) LockEmail(ctx context.Context, email string) error {
+	err := r.db.GetMasterDB(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "contractor-email:"+strings.ToLower(email)).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock contractor email: %w", err)
+	}
+	return nil
+}
@@ -4,4 +4,6 @@ type CreateContractorSvcReq struct {
 	Name  string
 	Email string
 	Phone string
+	// PayoutCurrency defaults to domain.DefaultCurrency when empty
+	PayoutCurrency string
 }
@@ -0,0 +1,8 @@
+package request
+
+// ListContractorsSvcReq filters the contractors, empty fields match every contractor
+type ListContractorsSvcReq struct {
+	Email string
+	// CompanyID narrows the contractors to the ones that have a job with the company
+	CompanyID string
+}
@@ -0,0 +1,8 @@
+package request
+
+type UpdateContractorSvcReq struct {
+	Name           string
+	Email          string
+	Phone          string
+	PayoutCurrency string
+}
@@ -2,26 +2,175 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/mercor/payment-service/internal/contractor/request"
 	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/static"
 )
 
 type ContractorService struct {
-	repo domain.ContractorRepository
+	repo    domain.ContractorRepository
+	jobRepo domain.JobRepositoryInterface
+	jobSvc  domain.JobServiceInterface
+	tx      postgres.Transactor
 }
 
-func NewContractorService(repo domain.ContractorRepository) *ContractorService {
-	return &ContractorService{repo: repo}
+func NewContractorService(repo domain.ContractorRepository, jobRepo domain.JobRepositoryInterface, jobSvc domain.JobServiceInterface, tx postgres.Transactor) *ContractorService {
+	return &ContractorService{repo: repo, jobRepo: jobRepo, jobSvc: jobSvc, tx: tx}
 }
 
-func (s *ContractorService) CreateContractor(ctx context.Context, req *request.CreateContractorSvcReq) error {
-	contractor := &domain.Contractor{
-		Model: &static.Model{},
-		Name:  req.Name,
-		Email: req.Email,
-		Phone: req.Phone,
+func (s *ContractorService) CreateContractor(ctx context.Context, req *request.CreateContractorSvcReq) (*domain.Contractor, error) {
+	payoutCurrency := req.PayoutCurrency
+	if payoutCurrency == "" {
+		payoutCurrency = domain.DefaultCurrency
 	}
-	return s.repo.Create(ctx, contractor)
+
+	contractor := domain.NewContractor(req.Name, req.Email, req.Phone, payoutCurrency)
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if err := s.claimEmail(ctx, req.Email, ""); err != nil {
+			return err
+		}
+		return s.repo.Create(ctx, contractor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contractor, nil
+}
+
+func (s *ContractorService) GetContractorByID(ctx context.Context, id string) (*domain.Contractor, error) {
+	contractor, err := s.repo.GetByConditions(ctx, map[string]interface{}{"id": id})
+	if errors.Is(err, static.ErrRecordNotFound) {
+		return nil, apperror.NotFound(fmt.Sprintf("contractor %s does not exist", id))
+	}
+	return contractor, err
+}
+
+// GetContractors lists the contractors, only the one with the email when it's given and only the ones that have
+// a job with the company when it's given
+func (s *ContractorService) GetContractors(ctx context.Context, req *request.ListContractorsSvcReq, page pagination.Request) (*pagination.Page[domain.Contractor], error) {
+	filter := map[string]interface{}{}
+	if req.Email != "" {
+		filter["email"] = req.Email
+	}
+	if req.CompanyID != "" {
+		jobs, err := s.jobRepo.FindLatestWithFilter(ctx, map[string]interface{}{"company_id": req.CompanyID})
+		if err != nil {
+			return nil, err
+		}
+		if len(jobs) == 0 {
+			return &pagination.Page[domain.Contractor]{Data: []domain.Contractor{}}, nil
+		}
+
+		contractorIDs := make([]string, len(jobs))
+		for i, job := range jobs {
+			contractorIDs[i] = job.ContractorID
+		}
+		filter["id"] = contractorIDs
+	}
+	return s.repo.GetAllByConditionsPage(ctx, filter, page)
+}
+
+func (s *ContractorService) UpdateContractor(ctx context.Context, id string, req *request.UpdateContractorSvcReq) (*domain.Contractor, error) {
+	var contractor *domain.Contractor
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := s.GetContractorByID(ctx, id); err != nil {
+			return err
+		}
+		if err := s.claimEmail(ctx, req.Email, id); err != nil {
+			return err
+		}
+
+		err := s.repo.UpdatesByConditions(ctx, map[string]interface{}{"id": id}, map[string]interface{}{
+			"name":            req.Name,
+			"email":           req.Email,
+			"phone":           req.Phone,
+			"payout_currency": req.PayoutCurrency,
+			"updated_at":      time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		contractor, err = s.GetContractorByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contractor, nil
+}
+
+// DeactivateContractor stops the contractor from taking jobs and ends every job of the contractor that isn't ended
+// yet through the job service, paused and draft jobs included since they could otherwise be resumed.
+// It returns the contractor as persisted.
+func (s *ContractorService) DeactivateContractor(ctx context.Context, id string) (*domain.Contractor, error) {
+	var contractor *domain.Contractor
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		contractor, err = s.GetContractorByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !contractor.IsActive() {
+			return apperror.InvalidTransition(fmt.Sprintf("contractor %s is already deactivated", id))
+		}
+
+		jobs, err := s.jobRepo.FindLatestWithFilter(ctx, map[string]interface{}{"contractor_id": id})
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if job.Status == domain.JobStatusEnded {
+				continue
+			}
+			// The job must not have changed since it was listed
+			version := job.GetVersion()
+			if _, err := s.jobSvc.TransitionJob(ctx, job.GetID(), &version, domain.JobStatusEnded); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		err = s.repo.UpdatesByConditions(ctx, map[string]interface{}{"id": id}, map[string]interface{}{
+			"deactivated_at": now,
+			"updated_at":     now,
+		})
+		if err != nil {
+			return err
+		}
+
+		contractor, err = s.GetContractorByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contractor, nil
+}
+
+// claimEmail fails with a conflict when another contractor than exceptID already has the email,
+// the email stays locked until the transaction ends so no one else can claim it in between
+func (s *ContractorService) claimEmail(ctx context.Context, email, exceptID string) error {
+	if err := s.repo.LockEmail(ctx, email); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByConditions(ctx, map[string]interface{}{"email": email})
+	if errors.Is(err, static.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.GetID() != exceptID {
+		return apperror.Conflict(fmt.Sprintf("a contractor with email %s already exists", email))
+	}
+	return nil
 }
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mercor/payment-service/internal/contractor/request"
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/mercor/payment-service/pkg/repository/static"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubContractorRepo compares emails case-insensitively like the citext column does
+type stubContractorRepo struct {
+	domain.ContractorRepository
+	contractors []*domain.Contractor
+	updates     map[string]interface{}
+}
+
+func (r *stubContractorRepo) LockEmail(context.Context, string) error {
+	return nil
+}
+
+func (r *stubContractorRepo) GetByConditions(_ context.Context, conditions map[string]interface{}) (*domain.Contractor, error) {
+	for _, contractor := range r.contractors {
+		if id, ok := conditions["id"]; ok && contractor.GetID() == id {
+			return contractor, nil
+		}
+		if email, ok := conditions["email"]; ok && strings.EqualFold(contractor.Email, email.(string)) {
+			return contractor, nil
+		}
+	}
+	return nil, static.ErrRecordNotFound
+}
+
+func (r *stubContractorRepo) Create(_ context.Context, contractor *domain.Contractor) error {
+	contractor.SetID(fmt.Sprintf("contractor-%d", len(r.contractors)+1))
+	r.contractors = append(r.contractors, contractor)
+	return nil
+}
+
+// UpdatesByConditions records the updates and applies the deactivation to the stored contractor
+func (r *stubContractorRepo) UpdatesByConditions(_ context.Context, conditions map[string]interface{}, updates map[string]interface{}) error {
+	r.updates = updates
+	for _, contractor := range r.contractors {
+		if contractor.GetID() != conditions["id"] {
+			continue
+		}
+		if deactivatedAt, ok := updates["deactivated_at"].(time.Time); ok {
+			contractor.DeactivatedAt = &deactivatedAt
+		}
+		if updatedAt, ok := updates["updated_at"].(time.Time); ok {
+			contractor.UpdatedAt = updatedAt
+		}
+	}
+	return nil
+}
+
+func (r *stubContractorRepo) GetAllByConditionsPage(_ context.Context, conditions map[string]interface{}, _ pagination.Request) (*pagination.Page[domain.Contractor], error) {
+	page := &pagination.Page[domain.Contractor]{Data: []domain.Contractor{}}
+	for _, contractor := range r.contractors {
+		if email, ok := conditions["email"]; ok && !strings.EqualFold(contractor.Email, email.(string)) {
+			continue
+		}
+		if ids, ok := conditions["id"]; ok && !slices.Contains(ids.([]string), contractor.GetID()) {
+			continue
+		}
+		page.Data = append(page.Data, *contractor)
+	}
+	return page, nil
+}
+
+func newContractor(id, email string) *domain.Contractor {
+	contractor := domain.NewContractor("Ann", email, "555-0100", "USD")
+	contractor.SetID(id)
+	return contractor
+}
+
+func TestClaimEmail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should refuse to create a contractor with an email that differs only in case", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{newContractor("contractor-1", "ann@example.com")}}
+		svc := NewContractorService(repo, stubJobRepo{}, &stubJobService{}, postgrestest.Transactor{})
+
+		_, err := svc.CreateContractor(ctx, &request.CreateContractorSvcReq{Name: "Ann", Email: "Ann@Example.COM"})
+
+		appErr, ok := apperror.As(err)
+		if assert.True(t, ok, err) {
+			assert.Equal(t, apperror.CodeConflict, appErr.Code)
+			assert.Equal(t, "a contractor with email Ann@Example.COM already exists", appErr.Message)
+		}
+		assert.Len(t, repo.contractors, 1)
+	})
+
+	t.Run("should create a contractor with an unclaimed email", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{newContractor("contractor-1", "ann@example.com")}}
+		svc := NewContractorService(repo, stubJobRepo{}, &stubJobService{}, postgrestest.Transactor{})
+
+		contractor, err := svc.CreateContractor(ctx, &request.CreateContractorSvcReq{Name: "Bob", Email: "bob@example.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DefaultCurrency, contractor.PayoutCurrency)
+		assert.Len(t, repo.contractors, 2)
+	})
+
+	t.Run("should let a contractor change the case of its own email", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{newContractor("contractor-1", "ann@example.com")}}
+		svc := NewContractorService(repo, stubJobRepo{}, &stubJobService{}, postgrestest.Transactor{})
+
+		_, err := svc.UpdateContractor(ctx, "contractor-1", &request.UpdateContractorSvcReq{Name: "Ann", Email: "ANN@example.com", PayoutCurrency: "USD"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ANN@example.com", repo.updates["email"])
+	})
+
+	t.Run("should refuse to take the email of another contractor in another case", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{
+			newContractor("contractor-1", "ann@example.com"),
+			newContractor("contractor-2", "bob@example.com"),
+		}}
+		svc := NewContractorService(repo, stubJobRepo{}, &stubJobService{}, postgrestest.Transactor{})
+
+		_, err := svc.UpdateContractor(ctx, "contractor-2", &request.UpdateContractorSvcReq{Name: "Bob", Email: "ANN@EXAMPLE.COM", PayoutCurrency: "USD"})
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeConflict), err)
+		assert.Nil(t, repo.updates)
+	})
+}
+
+func TestGetContractors(t *testing.T) {
+	ctx := context.Background()
+	contractors := []*domain.Contractor{newContractor("contractor-1", "ann@example.com"), newContractor("contractor-2", "bob@example.com")}
+	jobs := []domain.Job{*domain.NewJob(domain.JobStatusActive, money.NewFromInt(10), "USD", "Engineer", "company-1", "contractor-1")}
+
+	tests := []struct {
+		name string
+		req  *request.ListContractorsSvcReq
+		jobs []domain.Job
+		want []string
+	}{
+		{
+			name: "should list every contractor without filters",
+			req:  &request.ListContractorsSvcReq{},
+			want: []string{"contractor-1", "contractor-2"},
+		},
+		{
+			name: "should find a contractor by email in any case",
+			req:  &request.ListContractorsSvcReq{Email: "BOB@example.com"},
+			want: []string{"contractor-2"},
+		},
+		{
+			name: "should let a company find a contractor it has a job with",
+			req:  &request.ListContractorsSvcReq{Email: "ann@example.com", CompanyID: "company-1"},
+			jobs: jobs,
+			want: []string{"contractor-1"},
+		},
+		{
+			name: "should not let a company find a contractor it has no job with",
+			req:  &request.ListContractorsSvcReq{Email: "bob@example.com", CompanyID: "company-1"},
+			jobs: jobs,
+			want: []string{},
+		},
+		{
+			name: "should find nothing for a company without jobs",
+			req:  &request.ListContractorsSvcReq{Email: "ann@example.com", CompanyID: "company-2"},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewContractorService(&stubContractorRepo{contractors: contractors}, stubJobRepo{jobs: tt.jobs}, &stubJobService{}, postgrestest.Transactor{})
+
+			page, err := svc.GetContractors(ctx, tt.req, pagination.Request{})
+
+			assert.NoError(t, err)
+			ids := []string{}
+			for _, contractor := range page.Data {
+				ids = append(ids, contractor.GetID())
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+// stubJobRepo serves the latest versions of its jobs
+type stubJobRepo struct {
+	domain.JobRepositoryInterface
+	jobs []domain.Job
+}
+
+func (r stubJobRepo) FindLatestWithFilter(context.Context, map[string]interface{}) ([]domain.Job, error) {
+	jobs := make([]domain.Job, len(r.jobs))
+	copy(jobs, r.jobs)
+	return jobs, nil
+}
+
+// stubJobService validates the transitions of the latest jobs like the job service and records the ones it made
+type stubJobService struct {
+	domain.JobServiceInterface
+	latest      map[string]domain.Job
+	transitions map[string]domain.JobStatus
+}
+
+func (s *stubJobService) TransitionJob(_ context.Context, id string, expectedVersion *int, status domain.JobStatus) (*domain.Job, error) {
+	latest := s.latest[id]
+	if expectedVersion != nil && latest.GetVersion() != *expectedVersion {
+		return nil, scd.ErrVersionConflict
+	}
+	if err := latest.Status.ValidateTransition(status); err != nil {
+		return nil, err
+	}
+	s.transitions[id] = status
+	latest.Status = status
+	return &latest, nil
+}
+
+func TestDeactivateContractor(t *testing.T) {
+	ctx := context.Background()
+	job := func(id string, version int, status domain.JobStatus) domain.Job {
+		job := domain.NewJob(status, money.NewFromInt(10), "USD", "Engineer", "company-1", "contractor-1")
+		job.SCDModel = &scd.SCDModel{ID: id, Version: version, UID: fmt.Sprintf("%s-v%d", id, version), IsLatest: true}
+		return *job
+	}
+	newJobs := func(jobs ...domain.Job) (stubJobRepo, *stubJobService) {
+		latest := map[string]domain.Job{}
+		for _, job := range jobs {
+			latest[job.GetID()] = job
+		}
+		return stubJobRepo{jobs: jobs}, &stubJobService{latest: latest, transitions: map[string]domain.JobStatus{}}
+	}
+
+	t.Run("should end every job that is not ended yet through the job service", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{newContractor("contractor-1", "ann@example.com")}}
+		jobRepo, jobSvc := newJobs(
+			job("draft", 1, domain.JobStatusDraft),
+			job("active", 1, domain.JobStatusActive),
+			job("extended", 1, domain.JobStatusExtended),
+			job("paused", 1, domain.JobStatusPaused),
+			job("ended", 1, domain.JobStatusEnded),
+		)
+		svc := NewContractorService(repo, jobRepo, jobSvc, postgrestest.Transactor{})
+
+		_, err := svc.DeactivateContractor(ctx, "contractor-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]domain.JobStatus{
+			"draft":    domain.JobStatusEnded,
+			"active":   domain.JobStatusEnded,
+			"extended": domain.JobStatusEnded,
+			"paused":   domain.JobStatusEnded,
+		}, jobSvc.transitions)
+	})
+
+	t.Run("should return the contractor as persisted", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{newContractor("contractor-1", "ann@example.com")}}
+		jobRepo, jobSvc := newJobs()
+		svc := NewContractorService(repo, jobRepo, jobSvc, postgrestest.Transactor{})
+
+		contractor, err := svc.DeactivateContractor(ctx, "contractor-1")
+
+		assert.NoError(t, err)
+		assert.False(t, contractor.IsActive())
+		assert.Equal(t, repo.updates["deactivated_at"], *contractor.DeactivatedAt)
+		assert.Equal(t, repo.updates["updated_at"], contractor.UpdatedAt)
+	})
+
+	t.Run("should not deactivate the contractor when a job changed since it was listed", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{newContractor("contractor-1", "ann@example.com")}}
+		jobRepo, jobSvc := newJobs(job("active", 1, domain.JobStatusActive))
+		jobSvc.latest["active"] = job("active", 2, domain.JobStatusActive)
+		svc := NewContractorService(repo, jobRepo, jobSvc, postgrestest.Transactor{})
+
+		_, err := svc.DeactivateContractor(ctx, "contractor-1")
+
+		assert.ErrorIs(t, err, scd.ErrVersionConflict)
+		assert.Nil(t, repo.updates)
+	})
+
+	t.Run("should refuse to deactivate a contractor twice", func(t *testing.T) {
+		repo := &stubContractorRepo{contractors: []*domain.Contractor{newContractor("contractor-1", "ann@example.com")}}
+		jobRepo, jobSvc := newJobs()
+		svc := NewContractorService(repo, jobRepo, jobSvc, postgrestest.Transactor{})
+
+		_, err := svc.DeactivateContractor(ctx, "contractor-1")
+		assert.NoError(t, err)
+
+		_, err = svc.DeactivateContractor(ctx, "contractor-1")
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeInvalidTransition), err)
+	})
+
+	t.Run("should not find a missing contractor", func(t *testing.T) {
+		jobRepo, jobSvc := newJobs()
+		svc := NewContractorService(&stubContractorRepo{}, jobRepo, jobSvc, postgrestest.Transactor{})
+
+		_, err := svc.DeactivateContractor(ctx, "contractor-1")
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeNotFound), err)
+	})
+}
@@ -0,0 +1,111 @@
+package company
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	svcreq "github.com/mercor/payment-service/internal/company/request"
+	"github.com/mercor/payment-service/internal/controller/company/request"
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/pagination"
+)
+
+type Controller struct {
+	svc domain.CompanyServiceInterface
+}
+
+var (
+	ctrl     *Controller
+	ctrlOnce sync.Once
+)
+
+func NewController(svc domain.CompanyServiceInterface) *Controller {
+	ctrlOnce.Do(func() {
+		ctrl = &Controller{
+			svc: svc,
+		}
+	})
+	return ctrl
+}
+
+// POST /api/v1/companies
+func (c *Controller) CreateCompany(ctx *gin.Context) {
+	var req *request.CreateCompanyCtrlReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	company, err := c.svc.CreateCompany(ctx, convertCreateCompanyCtrlReqToSvcReq(req))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, company)
+}
+
+// GET /api/v1/companies/:company_id
+func (c *Controller) GetCompanyByID(ctx *gin.Context) {
+	company, err := c.svc.GetCompanyByID(ctx, ctx.Param("company_id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, company)
+}
+
+// GET /api/v1/companies?limit=&cursor=
+func (c *Controller) GetCompanies(ctx *gin.Context) {
+	page, err := pagination.FromQuery(ctx)
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	companies, err := c.svc.GetCompanies(ctx, page)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, companies)
+}
+
+// PUT /api/v1/companies/:company_id
+func (c *Controller) UpdateCompany(ctx *gin.Context) {
+	var req *request.UpdateCompanyCtrlReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	company, err := c.svc.UpdateCompany(ctx, ctx.Param("company_id"), convertUpdateCompanyCtrlReqToSvcReq(req))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, company)
+}
+
+// DELETE /api/v1/companies/:company_id
+func (c *Controller) DeleteCompany(ctx *gin.Context) {
+	if err := c.svc.DeleteCompany(ctx, ctx.Param("company_id")); err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func convertCreateCompanyCtrlReqToSvcReq(req *request.CreateCompanyCtrlReq) *svcreq.CreateCompanySvcReq {
+	return &svcreq.CreateCompanySvcReq{
+		Name: req.Name,
+	}
+}
+
+func convertUpdateCompanyCtrlReqToSvcReq(req *request.UpdateCompanyCtrlReq) *svcreq.UpdateCompanySvcReq {
+	return &svcreq.UpdateCompanySvcReq{
+		Name: req.Name,
+	}
+}
@@ -0,0 +1,21 @@
+package company
+
+import (
+	"github.com/google/wire"
+	"github.com/mercor/payment-service/internal/company/repository"
+	service "github.com/mercor/payment-service/internal/company/service"
+	"github.com/mercor/payment-service/internal/domain"
+	jobRepository "github.com/mercor/payment-service/internal/job/repository"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+)
+
+var ProviderSet wire.ProviderSet = wire.NewSet(
+	NewController,
+	service.NewCompanyService,
+	repository.NewCompanyRepository,
+	jobRepository.NewJobRepository,
+
+	wire.Bind(new(domain.CompanyControllerInterface), new(*Controller)),
+	wire.Bind(new(domain.CompanyServiceInterface), new(*service.CompanyService)),
+	wire.Bind(new(postgres.Transactor), new(*postgres.DbCluster)),
+)
@@ -0,0 +1,9 @@
+package request
+
+type CreateCompanyCtrlReq struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type UpdateCompanyCtrlReq struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
@@ -8,6 +8,9 @@ import (
 	svcreq "github.com/mercor/payment-service/internal/contractor/request"
 	"github.com/mercor/payment-service/internal/controller/contractor/request"
 	"github.com/mercor/payment-service/internal/domain"
+	middlewares "github.com/mercor/payment-service/internal/middleware"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/pagination"
 )
 
 type Controller struct {
@@ -34,23 +37,104 @@ func (c *Controller) CreateContractor(ctx *gin.Context) {
 	// Binding and validation
 	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
 		return
 	}
 
-	err = c.svc.CreateContractor(ctx, convertCreateContractorCtrlReqToCreateContractorSvcReq(req))
+	contractor, err := c.svc.CreateContractor(ctx, convertCreateContractorCtrlReqToCreateContractorSvcReq(req))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+		apperror.Abort(ctx, err)
 		return
 	}
 
-	ctx.Status(http.StatusCreated)
+	ctx.JSON(http.StatusCreated, contractor)
+}
+
+// GET /api/v1/contractors/:contractor_id
+func (c *Controller) GetContractorByID(ctx *gin.Context) {
+	contractor, err := c.svc.GetContractorByID(ctx, ctx.Param("contractor_id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, contractor)
+}
+
+// GET /api/v1/contractors?email=&limit=&cursor=
+// The email is matched case-insensitively, companies only find the contractors they have a job with.
+func (c *Controller) GetContractors(ctx *gin.Context) {
+	page, err := pagination.FromQuery(ctx)
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	// Only admins may list every contractor, companies look their contractors up by email
+	req := &svcreq.ListContractorsSvcReq{Email: ctx.Query("email")}
+	userDetails, ok := middlewares.GetUserDetails(ctx)
+	if !ok {
+		apperror.Abort(ctx, apperror.Unauthorized("authentication required"))
+		return
+	}
+	if !userDetails.HasRole(middlewares.RoleAdmin) {
+		if req.Email == "" {
+			apperror.Abort(ctx, apperror.Forbidden("contractors can only be looked up by email"))
+			return
+		}
+		req.CompanyID = userDetails.ID
+	}
+
+	contractors, err := c.svc.GetContractors(ctx, req, page)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, contractors)
+}
+
+// PUT /api/v1/contractors/:contractor_id
+func (c *Controller) UpdateContractor(ctx *gin.Context) {
+	var req *request.UpdateContractorCtrlReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	contractor, err := c.svc.UpdateContractor(ctx, ctx.Param("contractor_id"), convertUpdateContractorCtrlReqToSvcReq(req))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, contractor)
+}
+
+// POST /api/v1/contractors/:contractor_id/deactivate
+func (c *Controller) DeactivateContractor(ctx *gin.Context) {
+	// The ended job versions record who deactivated the contractor
+	changeCtx := middlewares.WithChangeInfo(ctx, "contractor deactivated")
+
+	contractor, err := c.svc.DeactivateContractor(changeCtx, ctx.Param("contractor_id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, contractor)
 }
 
 func convertCreateContractorCtrlReqToCreateContractorSvcReq(req *request.CreateContractorCtrlReq) *svcreq.CreateContractorSvcReq {
 	return &svcreq.CreateContractorSvcReq{
-		Name:  req.Name,
-		Email: req.Email,
-		Phone: req.Phone,
+		Name:           req.Name,
+		Email:          req.Email,
+		Phone:          req.Phone,
+		PayoutCurrency: req.PayoutCurrency,
+	}
+}
+
+func convertUpdateContractorCtrlReqToSvcReq(req *request.UpdateContractorCtrlReq) *svcreq.UpdateContractorSvcReq {
+	return &svcreq.UpdateContractorSvcReq{
+		Name:           req.Name,
+		Email:          req.Email,
+		Phone:          req.Phone,
+		PayoutCurrency: req.PayoutCurrency,
 	}
 }
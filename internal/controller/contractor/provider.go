@@ -2,16 +2,25 @@ package contractor
 
 import (
 	"github.com/google/wire"
+	companyRepository "github.com/mercor/payment-service/internal/company/repository"
 	repository "github.com/mercor/payment-service/internal/contractor/repository"
 	service "github.com/mercor/payment-service/internal/contractor/service"
 	"github.com/mercor/payment-service/internal/domain"
+	jobRepository "github.com/mercor/payment-service/internal/job/repository"
+	jobService "github.com/mercor/payment-service/internal/job/service"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
 )
 
 var ProviderSet wire.ProviderSet = wire.NewSet(
 	NewController,
 	service.NewContractorService,
 	repository.NewContractorRepository,
+	jobRepository.NewJobRepository,
+	jobService.NewService,
+	companyRepository.NewCompanyRepository,
 
 	wire.Bind(new(domain.ContractorControllerInterface), new(*Controller)),
 	wire.Bind(new(domain.ContractorServiceInterface), new(*service.ContractorService)),
+	wire.Bind(new(domain.JobServiceInterface), new(*jobService.Service)),
+	wire.Bind(new(postgres.Transactor), new(*postgres.DbCluster)),
 )
@@ -2,7 +2,8 @@ package request
 
 // Request structs (similar to job/request)
 type CreateContractorCtrlReq struct {
-	Name  string `json:"name" binding:"required"`
-	Email string `json:"email" binding:"required,email"`
-	Phone string `json:"phone" binding:"required"`
+	Name           string `json:"name" binding:"required"`
+	Email          string `json:"email" binding:"required,email"`
+	Phone          string `json:"phone" binding:"required"`
+	PayoutCurrency string `json:"payout_currency" binding:"omitempty,iso4217"`
 }
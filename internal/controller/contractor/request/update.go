@@ -0,0 +1,8 @@
+package request
+
+type UpdateContractorCtrlReq struct {
+	Name           string `json:"name" binding:"required"`
+	Email          string `json:"email" binding:"required,email"`
+	Phone          string `json:"phone" binding:"required"`
+	PayoutCurrency string `json:"payout_currency" binding:"required,iso4217"`
+}
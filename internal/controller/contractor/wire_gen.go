@@ -8,8 +8,11 @@ package contractor
 
 import (
 	"context"
+	repository3 "github.com/mercor/payment-service/internal/company/repository"
 	"github.com/mercor/payment-service/internal/contractor/repository"
 	"github.com/mercor/payment-service/internal/contractor/service"
+	repository2 "github.com/mercor/payment-service/internal/job/repository"
+	"github.com/mercor/payment-service/internal/job/service"
 	"github.com/mercor/payment-service/pkg/db/sql/postgres"
 )
 
@@ -17,7 +20,10 @@ import (
 
 func Wire(ctx context.Context, db *postgres.DbCluster) (*Controller, error) {
 	contractorRepository := repository.NewContractorRepository(db)
-	contractorService := service.NewContractorService(contractorRepository)
+	jobRepositoryInterface := repository2.NewJobRepository(db)
+	companyRepository := repository3.NewCompanyRepository(db)
+	jobService := job.NewService(jobRepositoryInterface, companyRepository, contractorRepository, db)
+	contractorService := service.NewContractorService(contractorRepository, jobRepositoryInterface, jobService, db)
 	controller := NewController(contractorService)
 	return controller, nil
 }
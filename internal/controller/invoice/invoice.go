@@ -0,0 +1,103 @@
+package invoice
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/controller/invoice/request"
+	"github.com/mercor/payment-service/internal/domain"
+	svcreq "github.com/mercor/payment-service/internal/invoice/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/pagination"
+)
+
+type Controller struct {
+	svc domain.InvoiceServiceInterface
+}
+
+var (
+	ctrl     *Controller
+	ctrlOnce sync.Once
+)
+
+func NewController(svc domain.InvoiceServiceInterface) *Controller {
+	ctrlOnce.Do(func() {
+		ctrl = &Controller{
+			svc: svc,
+		}
+	})
+	return ctrl
+}
+
+// POST /api/v1/companies/:company_id/invoices
+func (c *Controller) DraftInvoice(ctx *gin.Context) {
+	var req *request.DraftInvoiceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	invoice, err := c.svc.DraftInvoice(ctx, convertDraftInvoiceRequestToSvcReq(ctx.Param("company_id"), req))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, invoice)
+}
+
+// GET /api/v1/companies/:company_id/invoices?limit=&cursor=
+func (c *Controller) GetInvoicesForCompany(ctx *gin.Context) {
+	page, err := pagination.FromQuery(ctx)
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	invoices, err := c.svc.GetInvoicesForCompany(ctx, ctx.Param("company_id"), page)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, invoices)
+}
+
+// GET /api/v1/invoices/:id
+func (c *Controller) GetInvoiceByID(ctx *gin.Context) {
+	invoice, err := c.svc.GetInvoiceByID(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, invoice)
+}
+
+// POST /api/v1/invoices/:id/finalize
+func (c *Controller) FinalizeInvoice(ctx *gin.Context) {
+	invoice, err := c.svc.FinalizeInvoice(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, invoice)
+}
+
+// POST /api/v1/invoices/:id/void
+func (c *Controller) VoidInvoice(ctx *gin.Context) {
+	invoice, err := c.svc.VoidInvoice(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, invoice)
+}
+
+func convertDraftInvoiceRequestToSvcReq(companyID string, req *request.DraftInvoiceRequest) *svcreq.DraftInvoiceSvcReq {
+	return &svcreq.DraftInvoiceSvcReq{
+		CompanyID: companyID,
+		TimeStart: req.TimeStart,
+		TimeEnd:   req.TimeEnd,
+		Currency:  req.Currency,
+	}
+}
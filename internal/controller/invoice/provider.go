@@ -0,0 +1,23 @@
+package invoice
+
+import (
+	"github.com/google/wire"
+	companyRepository "github.com/mercor/payment-service/internal/company/repository"
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/invoice/repository"
+	service "github.com/mercor/payment-service/internal/invoice/service"
+	paymentRepository "github.com/mercor/payment-service/internal/payment/repository"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+)
+
+var ProviderSet wire.ProviderSet = wire.NewSet(
+	NewController,
+	service.NewInvoiceService,
+	repository.NewInvoiceRepository,
+	paymentRepository.NewPaymentRepository,
+	companyRepository.NewCompanyRepository,
+
+	wire.Bind(new(domain.InvoiceControllerInterface), new(*Controller)),
+	wire.Bind(new(domain.InvoiceServiceInterface), new(*service.InvoiceService)),
+	wire.Bind(new(postgres.Transactor), new(*postgres.DbCluster)),
+)
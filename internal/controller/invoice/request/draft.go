@@ -0,0 +1,8 @@
+package request
+
+// DraftInvoiceRequest asks to bill a company for the line items of its jobs in a period and currency
+type DraftInvoiceRequest struct {
+	TimeStart int64  `json:"time_start" binding:"required"`
+	TimeEnd   int64  `json:"time_end" binding:"required,gtfield=TimeStart"`
+	Currency  string `json:"currency" binding:"required,iso4217"`
+}
@@ -1,17 +1,27 @@
 package job
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/authorization"
 	"github.com/mercor/payment-service/internal/controller/job/request"
 	"github.com/mercor/payment-service/internal/domain"
 	svcreq "github.com/mercor/payment-service/internal/job/request"
+	middlewares "github.com/mercor/payment-service/internal/middleware"
+	"github.com/mercor/payment-service/pkg/apperror"
+	uhttp "github.com/mercor/payment-service/pkg/http"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
 type Controller struct {
-	svc domain.JobServiceInterface
+	svc   domain.JobServiceInterface
+	authz *authorization.Authorizer
 }
 
 var (
@@ -19,10 +29,11 @@ var (
 	ctrlOnce sync.Once
 )
 
-func NewController(svc domain.JobServiceInterface) *Controller {
+func NewController(svc domain.JobServiceInterface, authz *authorization.Authorizer) *Controller {
 	ctrlOnce.Do(func() {
 		ctrl = &Controller{
-			svc: svc,
+			svc:   svc,
+			authz: authz,
 		}
 	})
 	return ctrl
@@ -34,42 +45,258 @@ func (c *Controller) CreateJob(ctx *gin.Context) {
 	// Binding and validation
 	err := ctx.ShouldBindJSON(&req)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	// Companies create jobs for themselves only
+	userDetails, ok := middlewares.GetUserDetails(ctx)
+	if !ok {
+		apperror.Abort(ctx, apperror.Unauthorized("authentication required"))
+		return
+	}
+	if err := c.authz.Company(ctx, userDetails, req.CompanyID); err != nil {
+		apperror.Abort(ctx, err)
 		return
 	}
 
 	err = c.svc.CreateJob(ctx, convertCreateJobCtrlReqToCreateJobSvcReq(req))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+		apperror.Abort(ctx, err)
 		return
 	}
 
 	ctx.Status(http.StatusCreated)
 }
 
-func (c *Controller) GetJobsByStatus(ctx *gin.Context) {
-	jobs, err := c.svc.GetJobsByStatus(ctx, "extended")
+// GET /api/v1/jobs/:id?version=
+func (c *Controller) GetJobByID(ctx *gin.Context) {
+	var version *int
+	if raw := ctx.Query("version"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			apperror.Abort(ctx, apperror.Validation(fmt.Sprintf("invalid version %q", raw)))
+			return
+		}
+		version = &parsed
+	}
+
+	job, err := c.svc.GetJobByID(ctx, ctx.Param("id"), version)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.Header(uhttp.HeaderETag, uhttp.VersionETag(job.GetVersion()))
+	ctx.JSON(http.StatusOK, job)
+}
+
+// GET /api/v1/jobs?status=&company_id=&contractor_id=&limit=&cursor=
+func (c *Controller) GetJobs(ctx *gin.Context) {
+	c.getJobs(ctx, &svcreq.ListJobsSvcReq{
+		Status:       ctx.Query("status"),
+		CompanyID:    ctx.Query("company_id"),
+		ContractorID: ctx.Query("contractor_id"),
+	})
+}
+
+// GET /api/v1/jobs/extended
+// Deprecated: use GET /api/v1/jobs?status=extended
+func (c *Controller) GetExtendedJobs(ctx *gin.Context) {
+	c.getJobs(ctx, &svcreq.ListJobsSvcReq{Status: string(domain.JobStatusExtended)})
+}
+
+func (c *Controller) getJobs(ctx *gin.Context, req *svcreq.ListJobsSvcReq) {
+	userDetails, ok := middlewares.GetUserDetails(ctx)
+	if !ok {
+		apperror.Abort(ctx, apperror.Unauthorized("authentication required"))
+		return
+	}
+	if err := c.authz.ScopeJobs(userDetails, req); err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	page, err := pagination.FromQuery(ctx)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	jobs, err := c.svc.GetJobs(ctx, req, page)
+	if err != nil {
+		apperror.Abort(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, jobs)
 }
 
+// PUT /api/v1/jobs/:id
+// An If-Match header carrying the ETag of the version the client last read makes the update conditional.
+func (c *Controller) UpdateJob(ctx *gin.Context) {
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	var req *request.UpdateJobCtrlReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	job, err := c.svc.UpdateJob(ctx, ctx.Param("id"), expectedVersion, convertUpdateJobCtrlReqToSvcReq(req))
+	c.respondWithVersion(ctx, job, expectedVersion, err)
+}
+
+// PATCH /api/v1/jobs/:id
+// An If-Match header carrying the ETag of the version the client last read makes the update conditional.
+func (c *Controller) PatchJob(ctx *gin.Context) {
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	var req *request.PatchJobCtrlReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+	if req.Status == nil && req.Rate == nil && req.Currency == nil && req.Title == nil {
+		apperror.Abort(ctx, apperror.Validation("no fields to update"))
+		return
+	}
+
+	job, err := c.svc.PatchJob(ctx, ctx.Param("id"), expectedVersion, convertPatchJobCtrlReqToSvcReq(req))
+	c.respondWithVersion(ctx, job, expectedVersion, err)
+}
+
+// POST /api/v1/jobs/:id/activate
+func (c *Controller) ActivateJob(ctx *gin.Context) {
+	c.transitionJob(ctx, domain.JobStatusActive)
+}
+
+// POST /api/v1/jobs/:id/extend
+func (c *Controller) ExtendJob(ctx *gin.Context) {
+	c.transitionJob(ctx, domain.JobStatusExtended)
+}
+
+// POST /api/v1/jobs/:id/pause
+func (c *Controller) PauseJob(ctx *gin.Context) {
+	c.transitionJob(ctx, domain.JobStatusPaused)
+}
+
+// POST /api/v1/jobs/:id/end
+func (c *Controller) EndJob(ctx *gin.Context) {
+	c.transitionJob(ctx, domain.JobStatusEnded)
+}
+
+// transitionJob is conditional on an If-Match header like the updates
+func (c *Controller) transitionJob(ctx *gin.Context, status domain.JobStatus) {
+	expectedVersion, ok := parseIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	job, err := c.svc.TransitionJob(ctx, ctx.Param("id"), expectedVersion, status)
+	c.respondWithVersion(ctx, job, expectedVersion, err)
+}
+
+// respondWithVersion writes the new job version with its ETag, a conflict on an If-Match version fails its precondition
+func (c *Controller) respondWithVersion(ctx *gin.Context, job *domain.Job, expectedVersion *int, err error) {
+	if expectedVersion != nil && errors.Is(err, scd.ErrVersionConflict) {
+		err = apperror.PreconditionFailed("job was modified since the version in If-Match")
+	}
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.Header(uhttp.HeaderETag, uhttp.VersionETag(job.GetVersion()))
+	ctx.JSON(http.StatusOK, job)
+}
+
+// parseIfMatch returns the version of the If-Match header, nil when there is none
+func parseIfMatch(ctx *gin.Context) (*int, bool) {
+	version, conditional, err := uhttp.ParseVersionETag(ctx.GetHeader(uhttp.HeaderIfMatch))
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return nil, false
+	}
+	if !conditional {
+		return nil, true
+	}
+	return &version, true
+}
+
 func (c *Controller) GetActiveJobsForContractor(ctx *gin.Context) {
 	contractorID := ctx.Param("contractor_id")
-	jobs, err := c.svc.GetActiveJobsForContractor(ctx, contractorID)
+	page, err := pagination.FromQuery(ctx)
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	jobs, err := c.svc.GetActiveJobsForContractor(ctx, contractorID, page)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+		apperror.Abort(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, jobs)
 }
 
+// GET /api/v1/jobs/:id/history
+func (c *Controller) GetJobHistory(ctx *gin.Context) {
+	history, err := c.svc.GetJobHistory(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
+}
+
+// POST /api/v1/admin/jobs/:id/revert
+func (c *Controller) RevertJob(ctx *gin.Context) {
+	var req *request.RevertJobCtrlReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	changeCtx := middlewares.WithChangeInfo(ctx, req.Reason)
+
+	job, err := c.svc.RevertJobToVersion(changeCtx, ctx.Param("id"), req.Version)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+func convertUpdateJobCtrlReqToSvcReq(req *request.UpdateJobCtrlReq) *svcreq.UpdateJobSvcReq {
+	return &svcreq.UpdateJobSvcReq{
+		Status:   req.Status,
+		Rate:     req.Rate,
+		Currency: req.Currency,
+		Title:    req.Title,
+	}
+}
+
+func convertPatchJobCtrlReqToSvcReq(req *request.PatchJobCtrlReq) *svcreq.PatchJobSvcReq {
+	return &svcreq.PatchJobSvcReq{
+		Status:   req.Status,
+		Rate:     req.Rate,
+		Currency: req.Currency,
+		Title:    req.Title,
+	}
+}
+
 func convertCreateJobCtrlReqToCreateJobSvcReq(req *request.CreateJobCtrlReq) *svcreq.CreateJobSvcReq {
 	return &svcreq.CreateJobSvcReq{
 		Status:       req.Status,
 		Rate:         req.Rate,
+		Currency:     req.Currency,
 		Title:        req.Title,
 		CompanyID:    req.CompanyID,
 		ContractorID: req.ContractorID,
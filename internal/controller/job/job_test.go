@@ -0,0 +1,146 @@
+package job
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/domain"
+	svcreq "github.com/mercor/payment-service/internal/job/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	uhttp "github.com/mercor/payment-service/pkg/http"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/mercor/payment-service/pkg/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubJobService fails every write with err, or returns version 4 of the job when err is nil
+type stubJobService struct {
+	domain.JobServiceInterface
+	err             error
+	expectedVersion *int
+}
+
+func (s *stubJobService) respond(expectedVersion *int, status domain.JobStatus) (*domain.Job, error) {
+	s.expectedVersion = expectedVersion
+	if s.err != nil {
+		return nil, s.err
+	}
+	job := domain.NewJob(status, money.NewFromInt(10), "USD", "Engineer", "company-1", "contractor-1")
+	job.SCDModel = &scd.SCDModel{ID: "job-1", Version: 4, UID: "job-1-v4", IsLatest: true}
+	return job, nil
+}
+
+func (s *stubJobService) UpdateJob(_ context.Context, _ string, expectedVersion *int, req *svcreq.UpdateJobSvcReq) (*domain.Job, error) {
+	return s.respond(expectedVersion, domain.JobStatus(req.Status))
+}
+
+func (s *stubJobService) TransitionJob(_ context.Context, _ string, expectedVersion *int, status domain.JobStatus) (*domain.Job, error) {
+	return s.respond(expectedVersion, status)
+}
+
+func serve(svc domain.JobServiceInterface, method, path, ifMatch, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	validator.Set()
+	engine := gin.New()
+	engine.Use(apperror.Middleware())
+	// NewController keeps the first controller it built, each test needs its own service
+	controller := &Controller{svc: svc}
+	engine.PUT("/jobs/:id", controller.UpdateJob)
+	engine.POST("/jobs/:id/end", controller.EndJob)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set(uhttp.HeaderIfMatch, ifMatch)
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestVersionConflicts(t *testing.T) {
+	const updateBody = `{"status":"active","rate":"20.00","currency":"USD","title":"Lead engineer"}`
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		ifMatch  string
+		err      error
+		wantCode int
+	}{
+		{
+			name:     "should fail the precondition of an update whose If-Match version is stale",
+			method:   http.MethodPut,
+			path:     "/jobs/job-1",
+			body:     updateBody,
+			ifMatch:  uhttp.VersionETag(2),
+			err:      scd.ErrVersionConflict,
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "should serve a conflict of an unconditional update",
+			method:   http.MethodPut,
+			path:     "/jobs/job-1",
+			body:     updateBody,
+			err:      scd.ErrVersionConflict,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "should fail the precondition of a transition whose If-Match version is stale",
+			method:   http.MethodPost,
+			path:     "/jobs/job-1/end",
+			ifMatch:  uhttp.VersionETag(2),
+			err:      scd.ErrVersionConflict,
+			wantCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:     "should serve a conflict of an unconditional transition",
+			method:   http.MethodPost,
+			path:     "/jobs/job-1/end",
+			err:      scd.ErrVersionConflict,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "should not turn an invalid transition into a failed precondition",
+			method:   http.MethodPost,
+			path:     "/jobs/job-1/end",
+			ifMatch:  uhttp.VersionETag(3),
+			err:      apperror.InvalidTransition("job is already ended"),
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "should reject a weak If-Match ETag",
+			method:   http.MethodPost,
+			path:     "/jobs/job-1/end",
+			ifMatch:  `W/"3"`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serve(&stubJobService{err: tt.err}, tt.method, tt.path, tt.ifMatch, tt.body)
+
+			assert.Equal(t, tt.wantCode, recorder.Code, recorder.Body.String())
+		})
+	}
+
+	t.Run("should pass the If-Match version of a transition and serve the ETag of the new version", func(t *testing.T) {
+		svc := &stubJobService{}
+
+		recorder := serve(svc, http.MethodPost, "/jobs/job-1/end", uhttp.VersionETag(3), "")
+
+		assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Equal(t, uhttp.VersionETag(4), recorder.Header().Get(uhttp.HeaderETag))
+		if assert.NotNil(t, svc.expectedVersion) {
+			assert.Equal(t, 3, *svc.expectedVersion)
+		}
+	})
+}
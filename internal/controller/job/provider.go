@@ -2,11 +2,17 @@ package job
 
 import (
 	"github.com/google/wire"
+	"github.com/mercor/payment-service/internal/authorization"
 	companyRepository "github.com/mercor/payment-service/internal/company/repository"
 	contractorRepository "github.com/mercor/payment-service/internal/contractor/repository"
 	"github.com/mercor/payment-service/internal/domain"
+	invoiceRepository "github.com/mercor/payment-service/internal/invoice/repository"
 	"github.com/mercor/payment-service/internal/job/repository"
 	service "github.com/mercor/payment-service/internal/job/service"
+	paymentRepository "github.com/mercor/payment-service/internal/payment/repository"
+	payoutRepository "github.com/mercor/payment-service/internal/payout/repository"
+	timelogRepository "github.com/mercor/payment-service/internal/timelog/repository"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
 )
 
 var ProviderSet wire.ProviderSet = wire.NewSet(
@@ -15,7 +21,13 @@ var ProviderSet wire.ProviderSet = wire.NewSet(
 	repository.NewJobRepository,
 	companyRepository.NewCompanyRepository,
 	contractorRepository.NewContractorRepository,
+	authorization.NewAuthorizer,
+	timelogRepository.NewTimelogRepository,
+	paymentRepository.NewPaymentRepository,
+	payoutRepository.NewPayoutRepository,
+	invoiceRepository.NewInvoiceRepository,
 
 	wire.Bind(new(domain.JobControllerInterface), new(*Controller)),
 	wire.Bind(new(domain.JobServiceInterface), new(*service.Service)),
+	wire.Bind(new(postgres.Transactor), new(*postgres.DbCluster)),
 )
@@ -1,9 +1,12 @@
 package request
 
+import "github.com/mercor/payment-service/pkg/money"
+
 type CreateJobCtrlReq struct {
-	Status       string  `json:"status"`
-	Rate         float64 `json:"rate"`
-	Title        string  `json:"title"`
-	CompanyID    string  `json:"company_id"`
-	ContractorID string  `json:"contractor_id"`
+	Status       string        `json:"status"`
+	Rate         money.Decimal `json:"rate" binding:"required,gt=0"`
+	Currency     string        `json:"currency" binding:"required,iso4217"`
+	Title        string        `json:"title"`
+	CompanyID    string        `json:"company_id"`
+	ContractorID string        `json:"contractor_id"`
 }
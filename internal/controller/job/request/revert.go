@@ -0,0 +1,7 @@
+package request
+
+// RevertJobCtrlReq names the job version to restore and the reason recorded on the new version
+type RevertJobCtrlReq struct {
+	Version int    `json:"version" binding:"required,min=1"`
+	Reason  string `json:"reason" binding:"required"`
+}
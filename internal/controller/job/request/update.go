@@ -0,0 +1,18 @@
+package request
+
+import "github.com/mercor/payment-service/pkg/money"
+
+type UpdateJobCtrlReq struct {
+	Status   string        `json:"status" binding:"required"`
+	Rate     money.Decimal `json:"rate" binding:"required,gt=0"`
+	Currency string        `json:"currency" binding:"required,iso4217"`
+	Title    string        `json:"title" binding:"required"`
+}
+
+// PatchJobCtrlReq carries only the fields of a job that should change
+type PatchJobCtrlReq struct {
+	Status   *string        `json:"status" binding:"omitempty,min=1"`
+	Rate     *money.Decimal `json:"rate" binding:"omitempty,gt=0"`
+	Currency *string        `json:"currency" binding:"omitempty,iso4217"`
+	Title    *string        `json:"title" binding:"omitempty,min=1"`
+}
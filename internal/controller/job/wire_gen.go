@@ -8,10 +8,15 @@ package job
 
 import (
 	"context"
+	"github.com/mercor/payment-service/internal/authorization"
 	repository2 "github.com/mercor/payment-service/internal/company/repository"
 	repository3 "github.com/mercor/payment-service/internal/contractor/repository"
+	repository4 "github.com/mercor/payment-service/internal/invoice/repository"
 	"github.com/mercor/payment-service/internal/job/repository"
 	"github.com/mercor/payment-service/internal/job/service"
+	repository5 "github.com/mercor/payment-service/internal/payment/repository"
+	repository6 "github.com/mercor/payment-service/internal/payout/repository"
+	repository7 "github.com/mercor/payment-service/internal/timelog/repository"
 	"github.com/mercor/payment-service/pkg/db/sql/postgres"
 )
 
@@ -21,7 +26,12 @@ func Wire(ctx context.Context, db *postgres.DbCluster) (*Controller, error) {
 	jobRepositoryInterface := repository.NewJobRepository(db)
 	companyRepository := repository2.NewCompanyRepository(db)
 	contractorRepository := repository3.NewContractorRepository(db)
-	service := job.NewService(jobRepositoryInterface, companyRepository, contractorRepository)
-	controller := NewController(service)
+	service := job.NewService(jobRepositoryInterface, companyRepository, contractorRepository, db)
+	timeLogRepositoryInterface := repository7.NewTimelogRepository(db)
+	paymentLineRepository := repository5.NewPaymentRepository(db)
+	payoutRepository := repository6.NewPayoutRepository(db)
+	invoiceRepository := repository4.NewInvoiceRepository(db)
+	authorizer := authorization.NewAuthorizer(jobRepositoryInterface, timeLogRepositoryInterface, paymentLineRepository, payoutRepository, invoiceRepository)
+	controller := NewController(service, authorizer)
 	return controller, nil
 }
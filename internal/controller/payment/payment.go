@@ -1,13 +1,21 @@
 package payment
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mercor/payment-service/internal/controller/payment/request"
+	"github.com/mercor/payment-service/internal/domain"
+	middlewares "github.com/mercor/payment-service/internal/middleware"
+	svcreq "github.com/mercor/payment-service/internal/payment/request"
 	"github.com/mercor/payment-service/internal/payment/service"
+	"github.com/mercor/payment-service/pkg/apperror"
+	uhttp "github.com/mercor/payment-service/pkg/http"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
 type PaymentController struct {
@@ -26,42 +34,213 @@ func NewPaymentController(svc *service.PaymentService) *PaymentController {
 	return ctrl
 }
 
-// GET /api/v1/contractors/:contractor_id/payment-line-items?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// GET /api/v1/contractors/:contractor_id/payment-line-items?time_start=&time_end=&limit=&cursor=
 func (c *PaymentController) GetPaymentLineItemsForContractorPeriod(ctx *gin.Context) {
 	contractorID := ctx.Param("contractor_id")
 	startTime, err := strconv.ParseInt(ctx.Query("time_start"), 10, 64)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
 		return
 	}
 
 	endTime, err := strconv.ParseInt(ctx.Query("time_end"), 10, 64)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
 		return
 	}
 
-	items, err := c.svc.GetPaymentLineItemsForContractorPeriod(ctx, contractorID, startTime, endTime)
+	page, err := pagination.FromQuery(ctx)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	items, err := c.svc.GetPaymentLineItemsForContractorPeriod(ctx, contractorID, startTime, endTime, page)
+	if err != nil {
+		apperror.Abort(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, items)
 }
 
+// GET /api/v1/contractors/:contractor_id/earnings?time_start=&time_end=&group_by=job|day|week|status
+func (c *PaymentController) GetEarningsForContractorPeriod(ctx *gin.Context) {
+	startTime, err := strconv.ParseInt(ctx.Query("time_start"), 10, 64)
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	endTime, err := strconv.ParseInt(ctx.Query("time_end"), 10, 64)
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	groupBy, err := domain.ParseEarningsGroupBy(ctx.Query("group_by"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	earnings, err := c.svc.GetEarningsForContractorPeriod(ctx, ctx.Param("contractor_id"), startTime, endTime, groupBy)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, earnings)
+}
+
+// GET /api/v1/payment-line-items/:id
+func (c *PaymentController) GetPaymentLineItemByID(ctx *gin.Context) {
+	item, err := c.svc.GetPaymentLineItemByID(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.Header(uhttp.HeaderETag, uhttp.VersionETag(item.GetVersion()))
+	ctx.JSON(http.StatusOK, item)
+}
+
+// GET /api/v1/payment-line-items/:id/history
+func (c *PaymentController) GetPaymentLineItemHistory(ctx *gin.Context) {
+	history, err := c.svc.GetPaymentLineItemHistory(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
+}
+
 // PUT /api/v1/payment-line-items/:id
+// An If-Match header carrying the ETag of the version the client last read makes the update conditional.
 func (c *PaymentController) UpdatePaymentLineItemByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 
+	expectedVersion, conditional, err := uhttp.ParseVersionETag(ctx.GetHeader(uhttp.HeaderIfMatch))
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
 	var updates *request.UpdatePaymentRequest
 	if err := ctx.ShouldBindJSON(&updates); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
 		return
 	}
 
-	if err := c.svc.UpdatePaymentLineItemByID(ctx, id, updates); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+	var item *domain.PaymentLineItem
+	if conditional {
+		item, err = c.svc.UpdatePaymentLineItemByIDIfVersion(ctx, id, expectedVersion, convertUpdatePaymentRequestToSvcReq(updates))
+	} else {
+		item, err = c.svc.UpdatePaymentLineItemByID(ctx, id, convertUpdatePaymentRequestToSvcReq(updates))
+	}
+	if conditional && errors.Is(err, scd.ErrVersionConflict) {
+		err = apperror.PreconditionFailed("payment line item was modified since the version in If-Match")
+	}
+	if err != nil {
+		apperror.Abort(ctx, err)
 		return
 	}
+
+	ctx.Header(uhttp.HeaderETag, uhttp.VersionETag(item.GetVersion()))
 	ctx.Status(http.StatusOK)
 }
+
+// PATCH /api/v1/payment-line-items/:id
+// An If-Match header carrying the ETag of the version the client last read makes the patch conditional.
+func (c *PaymentController) PatchPaymentLineItemByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	expectedVersion, conditional, err := uhttp.ParseVersionETag(ctx.GetHeader(uhttp.HeaderIfMatch))
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	var patch *request.PatchPaymentRequest
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	updates := convertPatchPaymentRequestToUpdates(patch)
+	if len(updates) == 0 {
+		apperror.Abort(ctx, apperror.Validation("no fields to update"))
+		return
+	}
+
+	var ifVersion *int
+	if conditional {
+		ifVersion = &expectedVersion
+	}
+	item, err := c.svc.PatchPaymentLineItemByID(ctx, id, ifVersion, updates)
+	if conditional && errors.Is(err, scd.ErrVersionConflict) {
+		err = apperror.PreconditionFailed("payment line item was modified since the version in If-Match")
+	}
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.Header(uhttp.HeaderETag, uhttp.VersionETag(item.GetVersion()))
+	ctx.JSON(http.StatusOK, item)
+}
+
+// POST /api/v1/admin/payment-line-items/:id/revert
+func (c *PaymentController) RevertPaymentLineItem(ctx *gin.Context) {
+	var req *request.RevertPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	changeCtx := middlewares.WithChangeInfo(ctx, req.Reason)
+
+	item, err := c.svc.RevertPaymentLineItemToVersion(changeCtx, ctx.Param("id"), req.Version)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+func convertUpdatePaymentRequestToSvcReq(req *request.UpdatePaymentRequest) *svcreq.UpdatePaymentLineItemSvcReq {
+	return &svcreq.UpdatePaymentLineItemSvcReq{
+		JobUID:         req.JobUID,
+		TimelogUID:     req.TimelogUID,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		PayoutCurrency: req.PayoutCurrency,
+		FxRate:         req.FxRate,
+		Status:         req.Status,
+	}
+}
+
+func convertPatchPaymentRequestToUpdates(req *request.PatchPaymentRequest) map[string]interface{} {
+	updates := make(map[string]interface{})
+	if req.JobUID != nil {
+		updates["job_uid"] = *req.JobUID
+	}
+	if req.TimelogUID != nil {
+		updates["timelog_uid"] = *req.TimelogUID
+	}
+	if req.Amount != nil {
+		updates["amount"] = *req.Amount
+	}
+	if req.Currency != nil {
+		updates["currency"] = *req.Currency
+	}
+	if req.PayoutCurrency != nil {
+		updates["payout_currency"] = *req.PayoutCurrency
+	}
+	if req.FxRate != nil {
+		updates["fx_rate"] = *req.FxRate
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+	return updates
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/mercor/payment-service/internal/domain"
 	"github.com/mercor/payment-service/internal/payment/repository"
 	"github.com/mercor/payment-service/internal/payment/service"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
 )
 
 var ProviderSet wire.ProviderSet = wire.NewSet(
@@ -14,4 +15,5 @@ var ProviderSet wire.ProviderSet = wire.NewSet(
 
 	wire.Bind(new(domain.PaymentLineControllerInterface), new(*PaymentController)),
 	wire.Bind(new(domain.PaymentLineServiceInterface), new(*service.PaymentService)),
+	wire.Bind(new(postgres.Transactor), new(*postgres.DbCluster)),
 )
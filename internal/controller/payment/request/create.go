@@ -1,8 +1,10 @@
 package request
 
+import "github.com/mercor/payment-service/pkg/money"
+
 // CreatePaymentRequest represents a request to create a new payment
 type CreatePaymentRequest struct {
-	ContractorID string  `json:"contractor_id" binding:"required"`
-	Amount       float64 `json:"amount" binding:"required"`
-	Description  string  `json:"description" binding:"required"`
+	ContractorID string        `json:"contractor_id" binding:"required"`
+	Amount       money.Decimal `json:"amount" binding:"required"`
+	Description  string        `json:"description" binding:"required"`
 }
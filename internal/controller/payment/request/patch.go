@@ -0,0 +1,14 @@
+package request
+
+import "github.com/mercor/payment-service/pkg/money"
+
+// PatchPaymentRequest carries only the columns of a payment line item that should change
+type PatchPaymentRequest struct {
+	JobUID         *string        `json:"job_uid" binding:"omitempty,min=1"`
+	TimelogUID     *string        `json:"timelog_uid" binding:"omitempty,min=1"`
+	Amount         *money.Decimal `json:"amount" binding:"omitempty,gte=0"`
+	Currency       *string        `json:"currency" binding:"omitempty,iso4217"`
+	PayoutCurrency *string        `json:"payout_currency" binding:"omitempty,iso4217"`
+	FxRate         *money.Decimal `json:"fx_rate" binding:"omitempty,gt=0"`
+	Status         *string        `json:"status" binding:"omitempty,min=1"`
+}
@@ -0,0 +1,6 @@
+package request
+
+type RevertPaymentRequest struct {
+	Version int    `json:"version" binding:"required,min=1"`
+	Reason  string `json:"reason" binding:"required"`
+}
@@ -1,8 +1,13 @@
 package request
 
+import "github.com/mercor/payment-service/pkg/money"
+
 type UpdatePaymentRequest struct {
-	JobUID     string  `json:"job_uid" binding:"required"`
-	TimelogUID string  `json:"timelog_uid" binding:"required"`
-	Amount     float64 `json:"amount" binding:"required"`
-	Status     string  `json:"status" binding:"required"`
+	JobUID         string        `json:"job_uid" binding:"required"`
+	TimelogUID     string        `json:"timelog_uid" binding:"required"`
+	Amount         money.Decimal `json:"amount" binding:"gte=0"`
+	Currency       string        `json:"currency" binding:"required,iso4217"`
+	PayoutCurrency string        `json:"payout_currency" binding:"required,iso4217"`
+	FxRate         money.Decimal `json:"fx_rate" binding:"required,gt=0"`
+	Status         string        `json:"status" binding:"required"`
 }
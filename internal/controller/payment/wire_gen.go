@@ -17,7 +17,7 @@ import (
 
 func Wire(ctx context.Context, db *postgres.DbCluster) (*PaymentController, error) {
 	paymentLineRepository := repository.NewPaymentRepository(db)
-	paymentService := service.NewPaymentService(paymentLineRepository)
+	paymentService := service.NewPaymentService(paymentLineRepository, db)
 	paymentController := NewPaymentController(paymentService)
 	return paymentController, nil
 }
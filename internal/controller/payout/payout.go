@@ -0,0 +1,103 @@
+package payout
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/controller/payout/request"
+	"github.com/mercor/payment-service/internal/domain"
+	svcreq "github.com/mercor/payment-service/internal/payout/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/pagination"
+)
+
+type Controller struct {
+	svc domain.PayoutServiceInterface
+}
+
+var (
+	ctrl     *Controller
+	ctrlOnce sync.Once
+)
+
+func NewController(svc domain.PayoutServiceInterface) *Controller {
+	ctrlOnce.Do(func() {
+		ctrl = &Controller{
+			svc: svc,
+		}
+	})
+	return ctrl
+}
+
+// POST /api/v1/contractors/:contractor_id/payouts
+func (c *Controller) CreatePayout(ctx *gin.Context) {
+	var req *request.CreatePayoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	payout, err := c.svc.CreatePayout(ctx, convertCreatePayoutRequestToSvcReq(ctx.Param("contractor_id"), req))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, payout)
+}
+
+// GET /api/v1/contractors/:contractor_id/payouts?limit=&cursor=
+func (c *Controller) GetPayoutsForContractor(ctx *gin.Context) {
+	page, err := pagination.FromQuery(ctx)
+	if err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	payouts, err := c.svc.GetPayoutsForContractor(ctx, ctx.Param("contractor_id"), page)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, payouts)
+}
+
+// GET /api/v1/payouts/:id
+func (c *Controller) GetPayoutByID(ctx *gin.Context) {
+	payout, err := c.svc.GetPayoutByID(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, payout)
+}
+
+// POST /api/v1/payouts/:id/settle
+func (c *Controller) SettlePayout(ctx *gin.Context) {
+	payout, err := c.svc.SettlePayout(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, payout)
+}
+
+// POST /api/v1/payouts/:id/cancel
+func (c *Controller) CancelPayout(ctx *gin.Context) {
+	payout, err := c.svc.CancelPayout(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, payout)
+}
+
+func convertCreatePayoutRequestToSvcReq(contractorID string, req *request.CreatePayoutRequest) *svcreq.CreatePayoutSvcReq {
+	return &svcreq.CreatePayoutSvcReq{
+		ContractorID: contractorID,
+		TimeStart:    req.TimeStart,
+		TimeEnd:      req.TimeEnd,
+		Currency:     req.Currency,
+	}
+}
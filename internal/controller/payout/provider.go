@@ -0,0 +1,23 @@
+package payout
+
+import (
+	"github.com/google/wire"
+	contractorRepository "github.com/mercor/payment-service/internal/contractor/repository"
+	"github.com/mercor/payment-service/internal/domain"
+	paymentRepository "github.com/mercor/payment-service/internal/payment/repository"
+	"github.com/mercor/payment-service/internal/payout/repository"
+	service "github.com/mercor/payment-service/internal/payout/service"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+)
+
+var ProviderSet wire.ProviderSet = wire.NewSet(
+	NewController,
+	service.NewPayoutService,
+	repository.NewPayoutRepository,
+	paymentRepository.NewPaymentRepository,
+	contractorRepository.NewContractorRepository,
+
+	wire.Bind(new(domain.PayoutControllerInterface), new(*Controller)),
+	wire.Bind(new(domain.PayoutServiceInterface), new(*service.PayoutService)),
+	wire.Bind(new(postgres.Transactor), new(*postgres.DbCluster)),
+)
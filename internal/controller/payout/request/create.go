@@ -0,0 +1,8 @@
+package request
+
+// CreatePayoutRequest asks to pay the approved line items of a contractor for a period
+type CreatePayoutRequest struct {
+	TimeStart int64  `json:"time_start" binding:"required"`
+	TimeEnd   int64  `json:"time_end" binding:"required,gtfield=TimeStart"`
+	Currency  string `json:"currency" binding:"omitempty,iso4217"`
+}
@@ -2,16 +2,35 @@ package timelog
 
 import (
 	"github.com/google/wire"
+	"github.com/mercor/payment-service/internal/authorization"
+	contractorRepository "github.com/mercor/payment-service/internal/contractor/repository"
 	"github.com/mercor/payment-service/internal/domain"
+	invoiceRepository "github.com/mercor/payment-service/internal/invoice/repository"
+	jobRepository "github.com/mercor/payment-service/internal/job/repository"
+	paymentRepository "github.com/mercor/payment-service/internal/payment/repository"
+	paymentService "github.com/mercor/payment-service/internal/payment/service"
+	payoutRepository "github.com/mercor/payment-service/internal/payout/repository"
 	repository "github.com/mercor/payment-service/internal/timelog/repository"
 	service "github.com/mercor/payment-service/internal/timelog/service"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/fx"
 )
 
 var ProviderSet wire.ProviderSet = wire.NewSet(
 	NewTimelogController,
 	service.NewTimelogService,
 	repository.NewTimelogRepository,
+	jobRepository.NewJobRepository,
+	paymentRepository.NewPaymentRepository,
+	paymentService.NewLineItemGenerator,
+	contractorRepository.NewContractorRepository,
+	fx.NewLocalProvider,
+	authorization.NewAuthorizer,
+	payoutRepository.NewPayoutRepository,
+	invoiceRepository.NewInvoiceRepository,
 
 	wire.Bind(new(domain.TimelogControllerInterface), new(*TimelogController)),
 	wire.Bind(new(domain.TimelogServiceInterface), new(*service.TimelogService)),
+	wire.Bind(new(domain.PaymentLineItemGeneratorInterface), new(*paymentService.LineItemGenerator)),
+	wire.Bind(new(postgres.Transactor), new(*postgres.DbCluster)),
 )
@@ -2,9 +2,14 @@ package request
 
 // CreateTimelogRequest represents a request to create a new timelog entry
 type CreateTimelogRequest struct {
-	ContractorID string  `json:"contractor_id" binding:"required"`
-	JobID        string  `json:"job_id" binding:"required"`
-	Date         string  `json:"date" binding:"required"`
-	Hours        float64 `json:"hours" binding:"required"`
-	Description  string  `json:"description"`
+	JobID     string `json:"job_id" binding:"required"`
+	TimeStart int64  `json:"time_start" binding:"required"`
+	TimeEnd   int64  `json:"time_end" binding:"required,gtfield=TimeStart"`
+	Duration  int64  `json:"duration" binding:"required,min=1"`
+	Type      string `json:"type" binding:"required"`
+}
+
+// CreateTimelogsRequest represents a request to create several timelog entries at once
+type CreateTimelogsRequest struct {
+	Timelogs []*CreateTimelogRequest `json:"timelogs" binding:"required,min=1,max=500,dive,required"`
 }
@@ -0,0 +1,7 @@
+package request
+
+// RevertTimelogRequest restores an earlier version of a timelog, its line item follows the restored hours
+type RevertTimelogRequest struct {
+	Version int    `json:"version" binding:"required,min=1"`
+	Reason  string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,11 @@
+package request
+
+// ApproveTimelogRequest approves the hours of a timelog, the reason is optional
+type ApproveTimelogRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RejectTimelogRequest rejects the hours of a timelog
+type RejectTimelogRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
@@ -1,16 +1,25 @@
 package timelog
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/authorization"
+	"github.com/mercor/payment-service/internal/controller/timelog/request"
+	middlewares "github.com/mercor/payment-service/internal/middleware"
+	svcreq "github.com/mercor/payment-service/internal/timelog/request"
 	"github.com/mercor/payment-service/internal/timelog/service"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/pagination"
 )
 
 type TimelogController struct {
-	svc *service.TimelogService
+	svc   *service.TimelogService
+	authz *authorization.Authorizer
 }
 
 var (
@@ -18,32 +27,183 @@ var (
 	ctrlOnce sync.Once
 )
 
-func NewTimelogController(svc *service.TimelogService) *TimelogController {
+func NewTimelogController(svc *service.TimelogService, authz *authorization.Authorizer) *TimelogController {
 	ctrlOnce.Do(func() {
-		ctrl = &TimelogController{svc: svc}
+		ctrl = &TimelogController{svc: svc, authz: authz}
 	})
 	return ctrl
 }
 
-// GET /api/v1/contractors/:contractor_id/timelogs?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
+// POST /api/v1/timelogs
+func (c *TimelogController) CreateTimelog(ctx *gin.Context) {
+	var req *request.CreateTimelogRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+	if err := c.authorizeJob(ctx, req.JobID); err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	timelog, err := c.svc.CreateTimelog(ctx, convertCreateTimelogRequestToSvcReq(req))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, timelog)
+}
+
+// POST /api/v1/timelogs/batch
+// The batch is all or nothing, the error of the first invalid entry is returned with its index.
+func (c *TimelogController) CreateTimelogs(ctx *gin.Context) {
+	var req *request.CreateTimelogsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	svcReqs := make([]*svcreq.CreateTimelogSvcReq, len(req.Timelogs))
+	for i, timelogReq := range req.Timelogs {
+		if err := c.authorizeJob(ctx, timelogReq.JobID); err != nil {
+			apperror.Abort(ctx, err)
+			return
+		}
+		svcReqs[i] = convertCreateTimelogRequestToSvcReq(timelogReq)
+	}
+
+	timelogs, err := c.svc.CreateTimelogs(ctx, svcReqs)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, timelogs)
+}
+
+// authorizeJob refuses logging time against a job of another contractor
+func (c *TimelogController) authorizeJob(ctx *gin.Context, jobID string) error {
+	userDetails, ok := middlewares.GetUserDetails(ctx)
+	if !ok {
+		return apperror.Unauthorized("authentication required")
+	}
+	return c.authz.Job(ctx, userDetails, jobID)
+}
+
+// GET /api/v1/contractors/:contractor_id/timelogs?time_start=&time_end=&limit=&cursor=
 func (c *TimelogController) GetTimelogsForContractorPeriod(ctx *gin.Context) {
 	contractorID := ctx.Param("contractor_id")
 	startTime, err := strconv.ParseInt(ctx.Query("time_start"), 10, 64)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
 		return
 	}
 
 	endTime, err := strconv.ParseInt(ctx.Query("time_end"), 10, 64)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
 		return
 	}
 
-	timelogs, err := c.svc.GetTimelogsForContractorPeriod(ctx, contractorID, startTime, endTime)
+	page, err := pagination.FromQuery(ctx)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, err)
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	timelogs, err := c.svc.GetTimelogsForContractorPeriod(ctx, contractorID, startTime, endTime, page)
+	if err != nil {
+		apperror.Abort(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, timelogs)
 }
+
+// GET /api/v1/timelogs/:id/history
+func (c *TimelogController) GetTimelogHistory(ctx *gin.Context) {
+	history, err := c.svc.GetTimelogHistory(ctx, ctx.Param("id"))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
+}
+
+// POST /api/v1/admin/timelogs/:id/revert
+func (c *TimelogController) RevertTimelog(ctx *gin.Context) {
+	var req *request.RevertTimelogRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	changeCtx := middlewares.WithChangeInfo(ctx, req.Reason)
+
+	timelog, err := c.svc.RevertTimelogToVersion(changeCtx, ctx.Param("id"), req.Version)
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, timelog)
+}
+
+// POST /api/v1/timelogs/:id/approve
+func (c *TimelogController) ApproveTimelog(ctx *gin.Context) {
+	// The body is optional when no reason is given
+	var req request.ApproveTimelogRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	timelog, err := c.svc.ApproveTimelog(ctx, convertReviewTimelogRequestToSvcReq(ctx, req.Reason))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, timelog)
+}
+
+// POST /api/v1/timelogs/:id/reject
+func (c *TimelogController) RejectTimelog(ctx *gin.Context) {
+	var req *request.RejectTimelogRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apperror.Abort(ctx, apperror.Validation(err.Error()))
+		return
+	}
+
+	timelog, err := c.svc.RejectTimelog(ctx, convertReviewTimelogRequestToSvcReq(ctx, req.Reason))
+	if err != nil {
+		apperror.Abort(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, timelog)
+}
+
+// convertReviewTimelogRequestToSvcReq takes the reviewer from the user authenticated by AuthenticateJWT
+func convertReviewTimelogRequestToSvcReq(ctx *gin.Context, reason string) *svcreq.ReviewTimelogSvcReq {
+	var reviewerID string
+	if userDetails, ok := middlewares.GetUserDetails(ctx); ok {
+		reviewerID = userDetails.ID
+	}
+
+	return &svcreq.ReviewTimelogSvcReq{
+		TimelogID:  ctx.Param("id"),
+		ReviewerID: reviewerID,
+		Reason:     reason,
+	}
+}
+
+func convertCreateTimelogRequestToSvcReq(req *request.CreateTimelogRequest) *svcreq.CreateTimelogSvcReq {
+	return &svcreq.CreateTimelogSvcReq{
+		JobID:     req.JobID,
+		TimeStart: req.TimeStart,
+		TimeEnd:   req.TimeEnd,
+		Duration:  req.Duration,
+		Type:      req.Type,
+	}
+}
@@ -8,16 +8,35 @@ package timelog
 
 import (
 	"context"
+	"github.com/mercor/payment-service/internal/authorization"
+	repository4 "github.com/mercor/payment-service/internal/contractor/repository"
+	repository6 "github.com/mercor/payment-service/internal/invoice/repository"
+	repository2 "github.com/mercor/payment-service/internal/job/repository"
+	repository3 "github.com/mercor/payment-service/internal/payment/repository"
+	service2 "github.com/mercor/payment-service/internal/payment/service"
+	repository5 "github.com/mercor/payment-service/internal/payout/repository"
 	"github.com/mercor/payment-service/internal/timelog/repository"
 	"github.com/mercor/payment-service/internal/timelog/service"
 	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/fx"
 )
 
 // Injectors from wire.go:
 
 func Wire(ctx context.Context, db *postgres.DbCluster) (*TimelogController, error) {
 	timeLogRepositoryInterface := repository.NewTimelogRepository(db)
-	timelogService := service.NewTimelogService(timeLogRepositoryInterface)
-	timelogController := NewTimelogController(timelogService)
+	jobRepositoryInterface := repository2.NewJobRepository(db)
+	paymentLineRepository := repository3.NewPaymentRepository(db)
+	contractorRepository := repository4.NewContractorRepository(db)
+	provider, err := fx.NewLocalProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lineItemGenerator := service2.NewLineItemGenerator(paymentLineRepository, jobRepositoryInterface, contractorRepository, provider)
+	timelogService := service.NewTimelogService(timeLogRepositoryInterface, jobRepositoryInterface, lineItemGenerator, db)
+	payoutRepository := repository5.NewPayoutRepository(db)
+	invoiceRepository := repository6.NewInvoiceRepository(db)
+	authorizer := authorization.NewAuthorizer(jobRepositoryInterface, timeLogRepositoryInterface, paymentLineRepository, payoutRepository, invoiceRepository)
+	timelogController := NewTimelogController(timelogService, authorizer)
 	return timelogController, nil
 }
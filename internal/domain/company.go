@@ -1,8 +1,12 @@
 package domain
 
 import (
+	"context"
 	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/company/request"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/static"
 	"gorm.io/gorm"
 )
@@ -15,13 +19,10 @@ type Company struct {
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
 }
 
-func NewCompany(name string, createdAt time.Time, updatedAt time.Time, deletedAt gorm.DeletedAt) *Company {
+func NewCompany(name string) *Company {
 	return &Company{
-		Model:     &static.Model{},
-		Name:      name,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
-		DeletedAt: deletedAt,
+		Model: &static.Model{},
+		Name:  name,
 	}
 }
 
@@ -32,3 +33,19 @@ func (Company) TableName() string {
 type CompanyRepository interface {
 	static.StaticRepository[Company]
 }
+
+type CompanyServiceInterface interface {
+	CreateCompany(ctx context.Context, req *request.CreateCompanySvcReq) (*Company, error)
+	GetCompanyByID(ctx context.Context, id string) (*Company, error)
+	GetCompanies(ctx context.Context, page pagination.Request) (*pagination.Page[Company], error)
+	UpdateCompany(ctx context.Context, id string, req *request.UpdateCompanySvcReq) (*Company, error)
+	DeleteCompany(ctx context.Context, id string) error
+}
+
+type CompanyControllerInterface interface {
+	CreateCompany(ctx *gin.Context)
+	GetCompanyByID(ctx *gin.Context)
+	GetCompanies(ctx *gin.Context)
+	UpdateCompany(ctx *gin.Context)
+	DeleteCompany(ctx *gin.Context)
+}
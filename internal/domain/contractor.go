@@ -6,41 +6,61 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/mercor/payment-service/internal/contractor/request"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/static"
 	"gorm.io/gorm"
 )
 
 type Contractor struct {
 	*static.Model
-	Name      string         `gorm:"column:name;not null"`
-	Email     string         `gorm:"column:email;not null"`
-	Phone     string         `gorm:"column:phone;not null"`
-	CreatedAt time.Time      `gorm:"column:created_at;not null"`
-	UpdatedAt time.Time      `gorm:"column:updated_at;not null"`
-	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+	Name string `gorm:"column:name;not null"`
+	// Email is a citext column, it is compared case-insensitively and unique among contractors that aren't deleted
+	Email string `gorm:"column:email;not null"`
+	Phone string `gorm:"column:phone;not null"`
+	// PayoutCurrency is the ISO-4217 currency the contractor is paid out in
+	PayoutCurrency string `gorm:"column:payout_currency;not null"`
+	// DeactivatedAt is set once the contractor can no longer take jobs
+	DeactivatedAt *time.Time     `gorm:"column:deactivated_at"`
+	CreatedAt     time.Time      `gorm:"column:created_at;not null"`
+	UpdatedAt     time.Time      `gorm:"column:updated_at;not null"`
+	DeletedAt     gorm.DeletedAt `gorm:"column:deleted_at"`
 }
 
 func (Contractor) TableName() string {
 	return "contractor"
 }
 
-func NewContractor(name string, email string, phone string) *Contractor {
+func NewContractor(name string, email string, phone string, payoutCurrency string) *Contractor {
 	return &Contractor{
-		Model: &static.Model{},
-		Name:  name,
-		Email: email,
-		Phone: phone,
+		Model:          &static.Model{},
+		Name:           name,
+		Email:          email,
+		Phone:          phone,
+		PayoutCurrency: payoutCurrency,
 	}
 }
 
+func (c Contractor) IsActive() bool {
+	return c.DeactivatedAt == nil
+}
+
 type ContractorRepository interface {
 	static.StaticRepository[Contractor]
+	LockEmail(ctx context.Context, email string) error
 }
 
 type ContractorServiceInterface interface {
-	CreateContractor(ctx context.Context, req *request.CreateContractorSvcReq) error
+	CreateContractor(ctx context.Context, req *request.CreateContractorSvcReq) (*Contractor, error)
+	GetContractorByID(ctx context.Context, id string) (*Contractor, error)
+	GetContractors(ctx context.Context, req *request.ListContractorsSvcReq, page pagination.Request) (*pagination.Page[Contractor], error)
+	UpdateContractor(ctx context.Context, id string, req *request.UpdateContractorSvcReq) (*Contractor, error)
+	DeactivateContractor(ctx context.Context, id string) (*Contractor, error)
 }
 
 type ContractorControllerInterface interface {
 	CreateContractor(ctx *gin.Context)
+	GetContractorByID(ctx *gin.Context)
+	GetContractors(ctx *gin.Context)
+	UpdateContractor(ctx *gin.Context)
+	DeactivateContractor(ctx *gin.Context)
 }
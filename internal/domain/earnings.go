@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/money"
+)
+
+// EarningsGroupBy is the dimension contractor earnings are aggregated by
+type EarningsGroupBy string
+
+const (
+	EarningsGroupByJob    EarningsGroupBy = "job"
+	EarningsGroupByDay    EarningsGroupBy = "day"
+	EarningsGroupByWeek   EarningsGroupBy = "week"
+	EarningsGroupByStatus EarningsGroupBy = "status"
+)
+
+// ParseEarningsGroupBy returns the dimension named by groupBy, jobs when it's empty
+func ParseEarningsGroupBy(groupBy string) (EarningsGroupBy, error) {
+	switch g := EarningsGroupBy(groupBy); g {
+	case "":
+		return EarningsGroupByJob, nil
+	case EarningsGroupByJob, EarningsGroupByDay, EarningsGroupByWeek, EarningsGroupByStatus:
+		return g, nil
+	default:
+		return "", apperror.Validation(fmt.Sprintf("unknown earnings group_by %q", groupBy))
+	}
+}
+
+// Earnings are the hours and amounts of the latest line items in one group. Amounts of different currencies are
+// never added up, so a group has one row per currency and payout currency.
+type Earnings struct {
+	// Key is the job ID, the UTC day or week start as YYYY-MM-DD, or the line item status
+	Key            string        `gorm:"column:key" json:"key"`
+	Currency       string        `gorm:"column:currency" json:"currency"`
+	PayoutCurrency string        `gorm:"column:payout_currency" json:"payout_currency"`
+	Duration       int64         `gorm:"column:duration" json:"-"`
+	Hours          money.Decimal `gorm:"-" json:"hours"`
+	Amount         money.Decimal `gorm:"column:amount" json:"amount"`
+	PayoutAmount   money.Decimal `gorm:"column:payout_amount" json:"payout_amount"`
+	LineItems      int64         `gorm:"column:line_items" json:"line_items"`
+}
+
+// EarningsSummary is the response of a contractor earnings query
+type EarningsSummary struct {
+	ContractorID string          `json:"contractor_id"`
+	TimeStart    int64           `json:"time_start"`
+	TimeEnd      int64           `json:"time_end"`
+	GroupBy      EarningsGroupBy `json:"group_by"`
+	Groups       []Earnings      `json:"groups"`
+}
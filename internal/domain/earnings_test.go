@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEarningsGroupBy(t *testing.T) {
+	t.Run("should group by job when no dimension is named", func(t *testing.T) {
+		groupBy, err := ParseEarningsGroupBy("")
+
+		assert.NoError(t, err)
+		assert.Equal(t, EarningsGroupByJob, groupBy)
+	})
+
+	t.Run("should accept every dimension", func(t *testing.T) {
+		for _, want := range []EarningsGroupBy{EarningsGroupByJob, EarningsGroupByDay, EarningsGroupByWeek, EarningsGroupByStatus} {
+			groupBy, err := ParseEarningsGroupBy(string(want))
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, groupBy)
+		}
+	})
+
+	t.Run("should reject an unknown dimension", func(t *testing.T) {
+		_, err := ParseEarningsGroupBy("month")
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeValidation))
+		assert.EqualError(t, err, `unknown earnings group_by "month"`)
+	})
+
+	t.Run("should not ignore the case of a dimension", func(t *testing.T) {
+		_, err := ParseEarningsGroupBy("Job")
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeValidation))
+	})
+}
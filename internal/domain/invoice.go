@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/invoice/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/static"
+)
+
+// InvoiceStatus is the billing status of an invoice
+type InvoiceStatus string
+
+const (
+	InvoiceStatusDraft     InvoiceStatus = "draft"
+	InvoiceStatusFinalized InvoiceStatus = "finalized"
+	InvoiceStatusVoid      InvoiceStatus = "void"
+)
+
+var invoiceTransitions = transitionTable[InvoiceStatus]{
+	InvoiceStatusDraft:     {InvoiceStatusFinalized, InvoiceStatusVoid},
+	InvoiceStatusFinalized: {InvoiceStatusVoid},
+	InvoiceStatusVoid:      {},
+}
+
+// ValidateTransition returns an invalid transition error when an invoice can't move from s to next,
+// an invoice can't be finalized or voided twice
+func (s InvoiceStatus) ValidateTransition(next InvoiceStatus) error {
+	if s == next {
+		return apperror.InvalidTransition(fmt.Sprintf("invoice is already %s", s))
+	}
+	return invoiceTransitions.validate("invoice", s, next)
+}
+
+// Invoice bills a company for the line items of its jobs in a billing period and currency
+type Invoice struct {
+	*static.Model
+	// Number is assigned from the invoice number sequence when the invoice is finalized
+	Number      *string       `gorm:"column:number"`
+	CompanyID   string        `gorm:"column:company_id;not null"`
+	PeriodStart int64         `gorm:"column:period_start;not null"`
+	PeriodEnd   int64         `gorm:"column:period_end;not null"`
+	Currency    string        `gorm:"column:currency;not null"`
+	TotalAmount money.Decimal `gorm:"column:total_amount;not null"`
+	Status      InvoiceStatus `gorm:"column:status;not null"`
+	FinalizedAt *time.Time    `gorm:"column:finalized_at"`
+	VoidedAt    *time.Time    `gorm:"column:voided_at"`
+	CreatedAt   time.Time     `gorm:"column:created_at;not null"`
+	UpdatedAt   time.Time     `gorm:"column:updated_at;not null"`
+	// Lines are loaded separately from the invoice_line table
+	Lines []InvoiceLine `gorm:"-"`
+}
+
+func (Invoice) TableName() string {
+	return "invoice"
+}
+
+// NewInvoice totals the amounts of the lines, it fails when the total is out of the money range
+func NewInvoice(companyID string, periodStart, periodEnd int64, currency string, lines []InvoiceLine) (*Invoice, error) {
+	total := money.Decimal{}
+	for _, line := range lines {
+		var err error
+		if total, err = total.Add(line.Amount); err != nil {
+			return nil, apperror.Validation(fmt.Sprintf("the invoice total of company %s is too large: %v", companyID, err))
+		}
+	}
+
+	return &Invoice{
+		Model:       &static.Model{},
+		CompanyID:   companyID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Currency:    currency,
+		TotalAmount: total,
+		Status:      InvoiceStatusDraft,
+		Lines:       lines,
+	}, nil
+}
+
+// InvoiceLine pins the version of a line item that an invoice bills.
+// A line item is on at most one invoice whose lines are not released, voiding an invoice releases its lines.
+type InvoiceLine struct {
+	*static.Model
+	InvoiceID          string        `gorm:"column:invoice_id;not null"`
+	PaymentLineItemID  string        `gorm:"column:payment_line_item_id;not null"`
+	PaymentLineItemUID string        `gorm:"column:payment_line_item_uid;not null"`
+	JobUID             string        `gorm:"column:job_uid;not null"`
+	TimelogUID         string        `gorm:"column:timelog_uid;not null"`
+	Amount             money.Decimal `gorm:"column:amount;not null"`
+	Released           bool          `gorm:"column:released;not null;default:false"`
+}
+
+func (InvoiceLine) TableName() string {
+	return "invoice_line"
+}
+
+// NewInvoiceLine pins the given version of a line item
+func NewInvoiceLine(paymentLineItem *PaymentLineItem) InvoiceLine {
+	return InvoiceLine{
+		Model:              &static.Model{},
+		PaymentLineItemID:  paymentLineItem.GetID(),
+		PaymentLineItemUID: paymentLineItem.GetUID(),
+		JobUID:             paymentLineItem.JobUID,
+		TimelogUID:         paymentLineItem.TimelogUID,
+		Amount:             paymentLineItem.Amount,
+	}
+}
+
+// StillBills reports whether the latest version of the line item bills what the line pinned. Status changes such as
+// approval or payment keep the billed amount, voiding the item or changing its amount, currency, job or timelog don't.
+func (l InvoiceLine) StillBills(latest *PaymentLineItem, currency string) bool {
+	return latest.Status != PaymentLineItemStatusVoided &&
+		latest.Amount.Equal(l.Amount) &&
+		latest.Currency == currency &&
+		latest.JobUID == l.JobUID &&
+		latest.TimelogUID == l.TimelogUID
+}
+
+type InvoiceRepository interface {
+	static.StaticRepository[Invoice]
+	FindLines(ctx context.Context, invoiceID string) ([]InvoiceLine, error)
+	CreateLines(ctx context.Context, lines []*InvoiceLine) error
+	ReleaseLines(ctx context.Context, invoiceID string) error
+	NextInvoiceNumber(ctx context.Context) (string, error)
+	LockCompany(ctx context.Context, companyID string) error
+}
+
+type InvoiceServiceInterface interface {
+	DraftInvoice(ctx context.Context, req *request.DraftInvoiceSvcReq) (*Invoice, error)
+	GetInvoiceByID(ctx context.Context, id string) (*Invoice, error)
+	GetInvoicesForCompany(ctx context.Context, companyID string, page pagination.Request) (*pagination.Page[Invoice], error)
+	FinalizeInvoice(ctx context.Context, id string) (*Invoice, error)
+	VoidInvoice(ctx context.Context, id string) (*Invoice, error)
+}
+
+type InvoiceControllerInterface interface {
+	DraftInvoice(ctx *gin.Context)
+	GetInvoiceByID(ctx *gin.Context)
+	GetInvoicesForCompany(ctx *gin.Context)
+	FinalizeInvoice(ctx *gin.Context)
+	VoidInvoice(ctx *gin.Context)
+}
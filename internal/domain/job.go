@@ -5,17 +5,77 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/mercor/payment-service/internal/job/request"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
+// JobStatus is the lifecycle status of a job
+type JobStatus string
+
+const (
+	JobStatusDraft    JobStatus = "draft"
+	JobStatusActive   JobStatus = "active"
+	JobStatusExtended JobStatus = "extended"
+	JobStatusPaused   JobStatus = "paused"
+	JobStatusEnded    JobStatus = "ended"
+)
+
+var jobTransitions = transitionTable[JobStatus]{
+	JobStatusDraft:    {JobStatusActive, JobStatusEnded},
+	JobStatusActive:   {JobStatusExtended, JobStatusPaused, JobStatusEnded},
+	JobStatusExtended: {JobStatusPaused, JobStatusEnded},
+	JobStatusPaused:   {JobStatusActive, JobStatusEnded},
+	JobStatusEnded:    {},
+}
+
+// ActiveJobStatuses are the statuses of jobs that time can be logged against
+var ActiveJobStatuses = []JobStatus{JobStatusActive, JobStatusExtended}
+
+// ParseJobStatus returns the job status named by status or a validation error
+func ParseJobStatus(status string) (JobStatus, error) {
+	return parseStatus(jobTransitions, "job", status)
+}
+
+// ValidateTransition returns an invalid transition error when a job can't move from s to next.
+// Staying in the same status is always allowed.
+func (s JobStatus) ValidateTransition(next JobStatus) error {
+	return jobTransitions.validate("job", s, next)
+}
+
+// ActiveJobStatusFilter is ActiveJobStatuses for repository filters, gorm only expands slices of built-in types into IN
+func ActiveJobStatusFilter() []string {
+	statuses := make([]string, len(ActiveJobStatuses))
+	for i, status := range ActiveJobStatuses {
+		statuses[i] = string(status)
+	}
+	return statuses
+}
+
+// IsActive reports whether time can be logged against a job in the status
+func (s JobStatus) IsActive() bool {
+	for _, status := range ActiveJobStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CanStartAs reports whether a job can be created in the status
+func (s JobStatus) CanStartAs() bool {
+	return s == JobStatusDraft || s == JobStatusActive
+}
+
 // Job represents a job entity in the system
 type Job struct {
 	*scd.SCDModel
-	Status       string  `gorm:"column:status;not null"`
-	Rate         float64 `gorm:"column:rate;not null"`
-	Title        string  `gorm:"column:title;not null"`
-	CompanyID    string  `gorm:"column:company_id;not null"`
-	ContractorID string  `gorm:"column:contractor_id;not null"`
+	Status       JobStatus     `gorm:"column:status;not null"`
+	Rate         money.Decimal `gorm:"column:rate;not null"`
+	Currency     string        `gorm:"column:currency;not null"`
+	Title        string        `gorm:"column:title;not null"`
+	CompanyID    string        `gorm:"column:company_id;not null"`
+	ContractorID string        `gorm:"column:contractor_id;not null"`
 }
 
 // TableName specifies the table name for the Job model
@@ -23,11 +83,12 @@ func (Job) TableName() string {
 	return "job"
 }
 
-func NewJob(status string, rate float64, title string, companyID string, contractorID string) *Job {
+func NewJob(status JobStatus, rate money.Decimal, currency string, title string, companyID string, contractorID string) *Job {
 	return &Job{
 		SCDModel:     &scd.SCDModel{},
 		Status:       status,
 		Rate:         rate,
+		Currency:     currency,
 		Title:        title,
 		CompanyID:    companyID,
 		ContractorID: contractorID,
@@ -40,12 +101,28 @@ type JobRepositoryInterface interface {
 
 type JobServiceInterface interface {
 	CreateJob(ctx context.Context, req *request.CreateJobSvcReq) error
-	GetJobsByStatus(ctx context.Context, status string) ([]Job, error)
-	GetActiveJobsForContractor(ctx context.Context, contractorID string) ([]Job, error)
+	GetJobByID(ctx context.Context, id string, version *int) (*Job, error)
+	GetJobs(ctx context.Context, req *request.ListJobsSvcReq, page pagination.Request) (*pagination.Page[Job], error)
+	UpdateJob(ctx context.Context, id string, expectedVersion *int, req *request.UpdateJobSvcReq) (*Job, error)
+	PatchJob(ctx context.Context, id string, expectedVersion *int, req *request.PatchJobSvcReq) (*Job, error)
+	TransitionJob(ctx context.Context, id string, expectedVersion *int, status JobStatus) (*Job, error)
+	GetActiveJobsForContractor(ctx context.Context, contractorID string, page pagination.Request) (*pagination.Page[Job], error)
+	GetJobHistory(ctx context.Context, id string) ([]scd.VersionHistory, error)
+	RevertJobToVersion(ctx context.Context, id string, version int) (*Job, error)
 }
 
 type JobControllerInterface interface {
 	CreateJob(ctx *gin.Context)
-	GetJobsByStatus(ctx *gin.Context)
+	GetJobByID(ctx *gin.Context)
+	GetJobs(ctx *gin.Context)
+	GetExtendedJobs(ctx *gin.Context)
+	UpdateJob(ctx *gin.Context)
+	PatchJob(ctx *gin.Context)
+	ActivateJob(ctx *gin.Context)
+	ExtendJob(ctx *gin.Context)
+	PauseJob(ctx *gin.Context)
+	EndJob(ctx *gin.Context)
 	GetActiveJobsForContractor(ctx *gin.Context)
+	GetJobHistory(ctx *gin.Context)
+	RevertJob(ctx *gin.Context)
 }
@@ -4,16 +4,60 @@ import (
 	"context"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/payment/request"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
+// DefaultCurrency is the currency of records that were created before currencies were introduced
+const DefaultCurrency = "USD"
+
+// PaymentLineItemStatus is the payment status of a line item
+type PaymentLineItemStatus string
+
+const (
+	PaymentLineItemStatusPending  PaymentLineItemStatus = "pending"
+	PaymentLineItemStatusApproved PaymentLineItemStatus = "approved"
+	PaymentLineItemStatusPaid     PaymentLineItemStatus = "paid"
+	PaymentLineItemStatusVoided   PaymentLineItemStatus = "voided"
+)
+
+var paymentLineItemTransitions = transitionTable[PaymentLineItemStatus]{
+	PaymentLineItemStatusPending:  {PaymentLineItemStatusApproved, PaymentLineItemStatusVoided},
+	PaymentLineItemStatusApproved: {PaymentLineItemStatusPaid, PaymentLineItemStatusVoided},
+	PaymentLineItemStatusPaid:     {},
+	PaymentLineItemStatusVoided:   {},
+}
+
+// IsFinal reports whether a line item in the status can no longer change
+func (s PaymentLineItemStatus) IsFinal() bool {
+	return len(paymentLineItemTransitions[s]) == 0
+}
+
+// ParsePaymentLineItemStatus returns the line item status named by status or a validation error
+func ParsePaymentLineItemStatus(status string) (PaymentLineItemStatus, error) {
+	return parseStatus(paymentLineItemTransitions, "payment line item", status)
+}
+
+// ValidateTransition returns an invalid transition error when a line item can't move from s to next.
+// Staying in the same status is always allowed.
+func (s PaymentLineItemStatus) ValidateTransition(next PaymentLineItemStatus) error {
+	return paymentLineItemTransitions.validate("payment line item", s, next)
+}
+
 // PaymentLineItem represents a payment line item entity in the system
 type PaymentLineItem struct {
 	*scd.SCDModel
-	JobUID     string  `gorm:"column:job_uid;not null;index"`
-	TimelogUID string  `gorm:"column:timelog_uid;not null;index"`
-	Amount     float64 `gorm:"column:amount;not null"`
-	Status     string  `gorm:"column:status;not null"`
+	JobUID     string        `gorm:"column:job_uid;not null;index"`
+	TimelogUID string        `gorm:"column:timelog_uid;not null;index"`
+	Amount     money.Decimal `gorm:"column:amount;not null"`
+	// Currency is the currency of Amount, the currency of the job rate
+	Currency string `gorm:"column:currency;not null"`
+	// PayoutCurrency is the currency of the contractor's payout and FxRate the units of it one unit of Currency bought
+	PayoutCurrency string                `gorm:"column:payout_currency;not null"`
+	FxRate         money.Decimal         `gorm:"column:fx_rate;not null"`
+	Status         PaymentLineItemStatus `gorm:"column:status;not null"`
 }
 
 // TableName specifies the table name for the PaymentLineItem model
@@ -21,27 +65,58 @@ func (PaymentLineItem) TableName() string {
 	return "payment_line_items"
 }
 
-func NewPaymentLineItem(jobUID, timelogUID string, amount float64, status string) *PaymentLineItem {
+func NewPaymentLineItem(jobUID, timelogUID string, amount money.Decimal, currency, payoutCurrency string, fxRate money.Decimal, status PaymentLineItemStatus) *PaymentLineItem {
 	return &PaymentLineItem{
-		SCDModel:   &scd.SCDModel{},
-		JobUID:     jobUID,
-		TimelogUID: timelogUID,
-		Amount:     amount,
-		Status:     status,
+		SCDModel:       &scd.SCDModel{},
+		JobUID:         jobUID,
+		TimelogUID:     timelogUID,
+		Amount:         amount,
+		Currency:       currency,
+		PayoutCurrency: payoutCurrency,
+		FxRate:         fxRate,
+		Status:         status,
 	}
 }
 
+// PayoutAmount is Amount converted to PayoutCurrency at FxRate, rounded half up to cents
+func (p PaymentLineItem) PayoutAmount() (money.Decimal, error) {
+	return p.Amount.Mul(p.FxRate, money.CentPlaces, money.RoundHalfUp)
+}
+
 type PaymentLineRepository interface {
 	scd.SCDRepository[PaymentLineItem]
 	FindByContractorAndPeriod(ctx context.Context, contractorID string, startTime, endTime int64) ([]PaymentLineItem, error)
+	FindByContractorAndPeriodPage(ctx context.Context, contractorID string, startTime, endTime int64, page pagination.Request) (*pagination.Page[PaymentLineItem], error)
+	SumEarningsByContractorAndPeriod(ctx context.Context, contractorID string, startTime, endTime int64, groupBy EarningsGroupBy) ([]Earnings, error)
+	FindLatestByTimelogID(ctx context.Context, timelogID string) (*PaymentLineItem, error)
+	IsPinned(ctx context.Context, id string) (bool, error)
+	FindBillableByCompanyAndPeriod(ctx context.Context, companyID string, startTime, endTime int64, currency string) ([]PaymentLineItem, error)
+	FindPayableByContractorAndPeriod(ctx context.Context, contractorID string, startTime, endTime int64, payoutCurrency string) ([]PaymentLineItem, error)
+}
+
+// PaymentLineItemGeneratorInterface keeps the line item of a timelog in line with the timelog and its job rate
+type PaymentLineItemGeneratorInterface interface {
+	GenerateForTimelog(ctx context.Context, timelog *Timelog) (*PaymentLineItem, error)
+	ApproveForTimelog(ctx context.Context, timelog *Timelog) (*PaymentLineItem, error)
 }
 
 type PaymentLineServiceInterface interface {
-	GetPaymentLineItemsForContractorPeriod(ctx context.Context, contractorID string, startTime, endTime int64) ([]PaymentLineItem, error)
-	UpdatePaymentLineItemByID(ctx context.Context, id string, updates map[string]interface{}) error
+	GetPaymentLineItemsForContractorPeriod(ctx context.Context, contractorID string, startTime, endTime int64, page pagination.Request) (*pagination.Page[PaymentLineItem], error)
+	GetEarningsForContractorPeriod(ctx context.Context, contractorID string, startTime, endTime int64, groupBy EarningsGroupBy) (*EarningsSummary, error)
+	GetPaymentLineItemByID(ctx context.Context, id string) (*PaymentLineItem, error)
+	GetPaymentLineItemHistory(ctx context.Context, id string) ([]scd.VersionHistory, error)
+	UpdatePaymentLineItemByID(ctx context.Context, id string, req *request.UpdatePaymentLineItemSvcReq) (*PaymentLineItem, error)
+	UpdatePaymentLineItemByIDIfVersion(ctx context.Context, id string, expectedVersion int, req *request.UpdatePaymentLineItemSvcReq) (*PaymentLineItem, error)
+	PatchPaymentLineItemByID(ctx context.Context, id string, expectedVersion *int, updates map[string]interface{}) (*PaymentLineItem, error)
+	RevertPaymentLineItemToVersion(ctx context.Context, id string, version int) (*PaymentLineItem, error)
 }
 
 type PaymentLineControllerInterface interface {
 	GetPaymentLineItemsForContractorPeriod(ctx *gin.Context)
+	GetEarningsForContractorPeriod(ctx *gin.Context)
+	GetPaymentLineItemByID(ctx *gin.Context)
+	GetPaymentLineItemHistory(ctx *gin.Context)
 	UpdatePaymentLineItemByID(ctx *gin.Context)
+	PatchPaymentLineItemByID(ctx *gin.Context)
+	RevertPaymentLineItem(ctx *gin.Context)
 }
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/payout/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/static"
+)
+
+// PayoutStatus is the settlement status of a payout
+type PayoutStatus string
+
+const (
+	PayoutStatusPending   PayoutStatus = "pending"
+	PayoutStatusSettled   PayoutStatus = "settled"
+	PayoutStatusCancelled PayoutStatus = "cancelled"
+)
+
+var payoutTransitions = transitionTable[PayoutStatus]{
+	PayoutStatusPending:   {PayoutStatusSettled, PayoutStatusCancelled},
+	PayoutStatusSettled:   {},
+	PayoutStatusCancelled: {},
+}
+
+// ValidateTransition returns an invalid transition error when a payout can't move from s to next,
+// unlike other statuses a payout can't be settled or cancelled twice
+func (s PayoutStatus) ValidateTransition(next PayoutStatus) error {
+	if s == next {
+		return apperror.InvalidTransition(fmt.Sprintf("payout is already %s", s))
+	}
+	return payoutTransitions.validate("payout", s, next)
+}
+
+// Payout pays the approved line items of a contractor for a period in the contractor's payout currency
+type Payout struct {
+	*static.Model
+	ContractorID string        `gorm:"column:contractor_id;not null"`
+	PeriodStart  int64         `gorm:"column:period_start;not null"`
+	PeriodEnd    int64         `gorm:"column:period_end;not null"`
+	Currency     string        `gorm:"column:currency;not null"`
+	TotalAmount  money.Decimal `gorm:"column:total_amount;not null"`
+	Status       PayoutStatus  `gorm:"column:status;not null"`
+	SettledAt    *time.Time    `gorm:"column:settled_at"`
+	CreatedAt    time.Time     `gorm:"column:created_at;not null"`
+	UpdatedAt    time.Time     `gorm:"column:updated_at;not null"`
+	// Items are loaded separately from the payout_item table
+	Items []PayoutItem `gorm:"-"`
+}
+
+func (Payout) TableName() string {
+	return "payout"
+}
+
+// NewPayout totals the payout amounts of the items, it fails when the total is out of the money range
+func NewPayout(contractorID string, periodStart, periodEnd int64, currency string, items []PayoutItem) (*Payout, error) {
+	total := money.Decimal{}
+	for _, item := range items {
+		var err error
+		if total, err = total.Add(item.PayoutAmount); err != nil {
+			return nil, apperror.Validation(fmt.Sprintf("the payout total of contractor %s is too large: %v", contractorID, err))
+		}
+	}
+
+	return &Payout{
+		Model:        &static.Model{},
+		ContractorID: contractorID,
+		PeriodStart:  periodStart,
+		PeriodEnd:    periodEnd,
+		Currency:     currency,
+		TotalAmount:  total,
+		Status:       PayoutStatusPending,
+		Items:        items,
+	}, nil
+}
+
+// PayoutItem freezes the version of a line item that a payout pays.
+// A line item is in at most one payout whose items are not released, cancelling a payout releases its items.
+type PayoutItem struct {
+	*static.Model
+	PayoutID           string        `gorm:"column:payout_id;not null"`
+	PaymentLineItemID  string        `gorm:"column:payment_line_item_id;not null"`
+	PaymentLineItemUID string        `gorm:"column:payment_line_item_uid;not null"`
+	Amount             money.Decimal `gorm:"column:amount;not null"`
+	Currency           string        `gorm:"column:currency;not null"`
+	FxRate             money.Decimal `gorm:"column:fx_rate;not null"`
+	PayoutAmount       money.Decimal `gorm:"column:payout_amount;not null"`
+	Released           bool          `gorm:"column:released;not null;default:false"`
+}
+
+func (PayoutItem) TableName() string {
+	return "payout_item"
+}
+
+// NewPayoutItem freezes the given version of a line item
+func NewPayoutItem(paymentLineItem *PaymentLineItem) (PayoutItem, error) {
+	payoutAmount, err := paymentLineItem.PayoutAmount()
+	if err != nil {
+		return PayoutItem{}, apperror.Validation(fmt.Sprintf("the payout amount of line item %s is too large: %v", paymentLineItem.GetID(), err))
+	}
+
+	return PayoutItem{
+		Model:              &static.Model{},
+		PaymentLineItemID:  paymentLineItem.GetID(),
+		PaymentLineItemUID: paymentLineItem.GetUID(),
+		Amount:             paymentLineItem.Amount,
+		Currency:           paymentLineItem.Currency,
+		FxRate:             paymentLineItem.FxRate,
+		PayoutAmount:       payoutAmount,
+	}, nil
+}
+
+type PayoutRepository interface {
+	static.StaticRepository[Payout]
+	FindItems(ctx context.Context, payoutID string) ([]PayoutItem, error)
+	CreateItems(ctx context.Context, items []*PayoutItem) error
+	ReleaseItems(ctx context.Context, payoutID string) error
+	LockContractor(ctx context.Context, contractorID string) error
+}
+
+type PayoutServiceInterface interface {
+	CreatePayout(ctx context.Context, req *request.CreatePayoutSvcReq) (*Payout, error)
+	GetPayoutByID(ctx context.Context, id string) (*Payout, error)
+	GetPayoutsForContractor(ctx context.Context, contractorID string, page pagination.Request) (*pagination.Page[Payout], error)
+	SettlePayout(ctx context.Context, id string) (*Payout, error)
+	CancelPayout(ctx context.Context, id string) (*Payout, error)
+}
+
+type PayoutControllerInterface interface {
+	CreatePayout(ctx *gin.Context)
+	GetPayoutByID(ctx *gin.Context)
+	GetPayoutsForContractor(ctx *gin.Context)
+	SettlePayout(ctx *gin.Context)
+	CancelPayout(ctx *gin.Context)
+}
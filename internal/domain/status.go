@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/mercor/payment-service/pkg/apperror"
+)
+
+// transitionTable lists the statuses each status may move to
+type transitionTable[S ~string] map[S][]S
+
+func (t transitionTable[S]) allows(from, to S) bool {
+	for _, next := range t[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (t transitionTable[S]) known(status S) bool {
+	_, ok := t[status]
+	return ok
+}
+
+// validate returns an invalid transition error when the table doesn't allow moving the entity from one status
+// to the other, staying in the same status is always allowed
+func (t transitionTable[S]) validate(entity string, from, to S) error {
+	if from != to && !t.allows(from, to) {
+		return apperror.InvalidTransition(fmt.Sprintf("%s cannot move from %s to %s", entity, from, to))
+	}
+	return nil
+}
+
+func parseStatus[S ~string](t transitionTable[S], entity string, status string) (S, error) {
+	parsed := S(status)
+	if !t.known(parsed) {
+		return parsed, apperror.Validation(fmt.Sprintf("unknown %s status %q", entity, status))
+	}
+	return parsed, nil
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentLineItemStatusTransitions(t *testing.T) {
+	t.Run("should allow the transitions of the table", func(t *testing.T) {
+		assert.NoError(t, PaymentLineItemStatusPending.ValidateTransition(PaymentLineItemStatusApproved))
+		assert.NoError(t, PaymentLineItemStatusApproved.ValidateTransition(PaymentLineItemStatusPaid))
+		assert.NoError(t, PaymentLineItemStatusPending.ValidateTransition(PaymentLineItemStatusVoided))
+		assert.NoError(t, PaymentLineItemStatusPending.ValidateTransition(PaymentLineItemStatusPending))
+	})
+
+	t.Run("should reject moving a paid line item back to pending", func(t *testing.T) {
+		err := PaymentLineItemStatusPaid.ValidateTransition(PaymentLineItemStatusPending)
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeInvalidTransition))
+		assert.EqualError(t, err, "payment line item cannot move from paid to pending")
+	})
+
+	t.Run("should reject paying a line item that was not approved", func(t *testing.T) {
+		err := PaymentLineItemStatusPending.ValidateTransition(PaymentLineItemStatusPaid)
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeInvalidTransition))
+	})
+
+	t.Run("should treat paid and voided as final", func(t *testing.T) {
+		assert.True(t, PaymentLineItemStatusPaid.IsFinal())
+		assert.True(t, PaymentLineItemStatusVoided.IsFinal())
+		assert.False(t, PaymentLineItemStatusApproved.IsFinal())
+	})
+}
+
+func TestParseStatus(t *testing.T) {
+	status, err := ParseJobStatus("paused")
+	assert.NoError(t, err)
+	assert.Equal(t, JobStatusPaused, status)
+
+	_, err = ParsePaymentLineItemStatus("not-paid")
+	assert.True(t, apperror.HasCode(err, apperror.CodeValidation))
+}
+
+func TestJobStatusTransitions(t *testing.T) {
+	assert.NoError(t, JobStatusActive.ValidateTransition(JobStatusExtended))
+	assert.NoError(t, JobStatusPaused.ValidateTransition(JobStatusActive))
+	assert.Error(t, JobStatusEnded.ValidateTransition(JobStatusActive))
+	assert.True(t, JobStatusExtended.IsActive())
+	assert.False(t, JobStatusPaused.IsActive())
+}
+
+func TestTimelogApprovalStatusTransitions(t *testing.T) {
+	assert.NoError(t, TimelogApprovalStatusPending.ValidateTransition(TimelogApprovalStatusApproved))
+	assert.NoError(t, TimelogApprovalStatusApproved.ValidateTransition(TimelogApprovalStatusRejected))
+	assert.EqualError(t, TimelogApprovalStatusApproved.ValidateTransition(TimelogApprovalStatusApproved), "timelog is already approved")
+	assert.True(t, apperror.HasCode(TimelogApprovalStatusRejected.ValidateTransition(TimelogApprovalStatusApproved), apperror.CodeInvalidTransition))
+}
@@ -2,12 +2,42 @@ package domain
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/internal/timelog/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
+// TimelogApprovalStatus is the review status of the hours of a timelog
+type TimelogApprovalStatus string
+
+const (
+	TimelogApprovalStatusPending  TimelogApprovalStatus = "pending"
+	TimelogApprovalStatusApproved TimelogApprovalStatus = "approved"
+	TimelogApprovalStatusRejected TimelogApprovalStatus = "rejected"
+)
+
+// Approved hours can still be rejected until they are paid, rejected hours have to be logged again
+var timelogApprovalTransitions = transitionTable[TimelogApprovalStatus]{
+	TimelogApprovalStatusPending:  {TimelogApprovalStatusApproved, TimelogApprovalStatusRejected},
+	TimelogApprovalStatusApproved: {TimelogApprovalStatusRejected},
+	TimelogApprovalStatusRejected: {},
+}
+
+// ValidateTransition returns an invalid transition error when a timelog can't move from s to next,
+// a timelog can't be approved or rejected twice
+func (s TimelogApprovalStatus) ValidateTransition(next TimelogApprovalStatus) error {
+	if s == next {
+		return apperror.InvalidTransition(fmt.Sprintf("timelog is already %s", s))
+	}
+	return timelogApprovalTransitions.validate("timelog", s, next)
+}
+
 // Timelog represents a time logging entity in the system
+// TimeStart and TimeEnd are Unix timestamps in milliseconds and Duration is in milliseconds
 type Timelog struct {
 	*scd.SCDModel
 	Duration  int64  `gorm:"column:duration;not null"`
@@ -15,6 +45,14 @@ type Timelog struct {
 	TimeEnd   int64  `gorm:"column:time_end;not null"`
 	Type      string `gorm:"column:type;not null"`
 	JobUID    string `gorm:"column:job_uid;not null;index"`
+	// Only approved timelog versions get a payment line item
+	ApprovalStatus TimelogApprovalStatus `gorm:"column:approval_status;not null"`
+	ApproverID     *string               `gorm:"column:approver_id"`
+	ApprovalReason *string               `gorm:"column:approval_reason"`
+}
+
+func (t Timelog) IsApproved() bool {
+	return t.ApprovalStatus == TimelogApprovalStatusApproved
 }
 
 // TableName specifies the table name for the Timelog model
@@ -22,15 +60,43 @@ func (Timelog) TableName() string {
 	return "timelog"
 }
 
+func NewTimelog(duration int64, timeStart int64, timeEnd int64, timelogType string, jobUID string) *Timelog {
+	return &Timelog{
+		SCDModel:  &scd.SCDModel{},
+		Duration:  duration,
+		TimeStart: timeStart,
+		TimeEnd:   timeEnd,
+		Type:      timelogType,
+		JobUID:    jobUID,
+
+		ApprovalStatus: TimelogApprovalStatusPending,
+	}
+}
+
 type TimeLogRepositoryInterface interface {
 	scd.SCDRepository[Timelog]
 	FindByContractorAndPeriod(ctx context.Context, contractorID string, startDate, endDate int64) ([]Timelog, error)
+	FindByContractorAndPeriodPage(ctx context.Context, contractorID string, startDate, endDate int64, page pagination.Request) (*pagination.Page[Timelog], error)
+	FindOverlapping(ctx context.Context, contractorID string, timeStart, timeEnd int64) ([]Timelog, error)
+	LockContractor(ctx context.Context, contractorID string) error
 }
 
 type TimelogServiceInterface interface {
-	GetTimelogsForContractorPeriod(ctx context.Context, contractorID string, startDate, endDate int64) ([]Timelog, error)
+	CreateTimelog(ctx context.Context, req *request.CreateTimelogSvcReq) (*Timelog, error)
+	CreateTimelogs(ctx context.Context, reqs []*request.CreateTimelogSvcReq) ([]Timelog, error)
+	GetTimelogsForContractorPeriod(ctx context.Context, contractorID string, startDate, endDate int64, page pagination.Request) (*pagination.Page[Timelog], error)
+	GetTimelogHistory(ctx context.Context, id string) ([]scd.VersionHistory, error)
+	RevertTimelogToVersion(ctx context.Context, id string, version int) (*Timelog, error)
+	ApproveTimelog(ctx context.Context, req *request.ReviewTimelogSvcReq) (*Timelog, error)
+	RejectTimelog(ctx context.Context, req *request.ReviewTimelogSvcReq) (*Timelog, error)
 }
 
 type TimelogControllerInterface interface {
+	CreateTimelog(ctx *gin.Context)
+	CreateTimelogs(ctx *gin.Context)
 	GetTimelogsForContractorPeriod(ctx *gin.Context)
+	GetTimelogHistory(ctx *gin.Context)
+	RevertTimelog(ctx *gin.Context)
+	ApproveTimelog(ctx *gin.Context)
+	RejectTimelog(ctx *gin.Context)
 }
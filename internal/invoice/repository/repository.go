@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/repository/static"
+)
+
+const lineBatchSize = 500
+
+var (
+	repo     *InvoiceRepository
+	repoOnce sync.Once
+)
+
+type InvoiceRepository struct {
+	static.StaticRepository[domain.Invoice]
+	lines static.StaticRepository[domain.InvoiceLine]
+	db    *postgres.DbCluster
+}
+
+func NewInvoiceRepository(db *postgres.DbCluster) domain.InvoiceRepository {
+	repoOnce.Do(func() {
+		repo = &InvoiceRepository{
+			db:               db,
+			StaticRepository: static.NewStaticRepository[domain.Invoice](db),
+			lines:            static.NewStaticRepository[domain.InvoiceLine](db),
+		}
+	})
+
+	return repo
+}
+
+func (r *InvoiceRepository) FindLines(ctx context.Context, invoiceID string) ([]domain.InvoiceLine, error) {
+	return r.lines.GetAllByConditions(ctx, map[string]interface{}{"invoice_id": invoiceID})
+}
+
+func (r *InvoiceRepository) CreateLines(ctx context.Context, lines []*domain.InvoiceLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	return r.lines.CreateInBatch(ctx, lines, lineBatchSize)
+}
+
+// ReleaseLines lets the line items of a void invoice be billed by another invoice
+func (r *InvoiceRepository) ReleaseLines(ctx context.Context, invoiceID string) error {
+	return r.lines.UpdatesByConditions(ctx, map[string]interface{}{"invoice_id": invoiceID}, map[string]interface{}{"released": true})
+}
+
+// NextInvoiceNumber takes the next number of the invoice number sequence
+func (r *InvoiceRepository) NextInvoiceNumber(ctx context.Context) (string, error) {
+	var number int64
+	err := r.db.GetMasterDB(ctx).Raw("SELECT nextval('invoice_number_seq')").Scan(&number).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to take the next invoice number: %w", err)
+	}
+	return fmt.Sprintf("INV-%06d", number), nil
+}
+
+// LockCompany serializes the invoice writes of a company until the surrounding transaction ends
+func (r *InvoiceRepository) LockCompany(ctx context.Context, companyID string) error {
+	err := r.db.GetMasterDB(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "invoice:"+companyID).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock invoices of company %s: %w", companyID, err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package request
+
+// DraftInvoiceSvcReq asks to bill a company for the line items of its jobs in a period and currency
+type DraftInvoiceSvcReq struct {
+	CompanyID string `json:"company_id"`
+	TimeStart int64  `json:"time_start"`
+	TimeEnd   int64  `json:"time_end"`
+	Currency  string `json:"currency"`
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/invoice/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/static"
+)
+
+type InvoiceService struct {
+	repo        domain.InvoiceRepository
+	paymentRepo domain.PaymentLineRepository
+	companyRepo domain.CompanyRepository
+	tx          postgres.Transactor
+}
+
+func NewInvoiceService(
+	repo domain.InvoiceRepository,
+	paymentRepo domain.PaymentLineRepository,
+	companyRepo domain.CompanyRepository,
+	tx postgres.Transactor,
+) *InvoiceService {
+	return &InvoiceService{repo: repo, paymentRepo: paymentRepo, companyRepo: companyRepo, tx: tx}
+}
+
+// DraftInvoice pins the latest line items of the company's jobs for the period that no other invoice bills
+func (s *InvoiceService) DraftInvoice(ctx context.Context, req *request.DraftInvoiceSvcReq) (*domain.Invoice, error) {
+	if req.TimeEnd <= req.TimeStart {
+		return nil, apperror.Validation("time_end must be after time_start")
+	}
+
+	_, err := s.companyRepo.GetByConditions(ctx, map[string]interface{}{"id": req.CompanyID})
+	if errors.Is(err, static.ErrRecordNotFound) {
+		return nil, apperror.NotFound(fmt.Sprintf("company %s does not exist", req.CompanyID))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var invoice *domain.Invoice
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		// Concurrent drafts of the company would select the same line items
+		if err := s.repo.LockCompany(ctx, req.CompanyID); err != nil {
+			return err
+		}
+
+		paymentLineItems, err := s.paymentRepo.FindBillableByCompanyAndPeriod(ctx, req.CompanyID, req.TimeStart, req.TimeEnd, req.Currency)
+		if err != nil {
+			return err
+		}
+		if len(paymentLineItems) == 0 {
+			return apperror.Validation(fmt.Sprintf("company %s has no %s line items to bill for the period", req.CompanyID, req.Currency))
+		}
+
+		lines := make([]domain.InvoiceLine, len(paymentLineItems))
+		for i := range paymentLineItems {
+			lines[i] = domain.NewInvoiceLine(&paymentLineItems[i])
+		}
+
+		invoice, err = domain.NewInvoice(req.CompanyID, req.TimeStart, req.TimeEnd, req.Currency, lines)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, invoice); err != nil {
+			return err
+		}
+
+		linePtrs := make([]*domain.InvoiceLine, len(invoice.Lines))
+		for i := range invoice.Lines {
+			invoice.Lines[i].InvoiceID = invoice.GetID()
+			linePtrs[i] = &invoice.Lines[i]
+		}
+		return s.repo.CreateLines(ctx, linePtrs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (s *InvoiceService) GetInvoiceByID(ctx context.Context, id string) (*domain.Invoice, error) {
+	invoice, err := s.repo.GetByConditions(ctx, map[string]interface{}{"id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Lines, err = s.repo.FindLines(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) GetInvoicesForCompany(ctx context.Context, companyID string, page pagination.Request) (*pagination.Page[domain.Invoice], error) {
+	return s.repo.GetAllByConditionsPage(ctx, map[string]interface{}{"company_id": companyID}, page)
+}
+
+// FinalizeInvoice numbers a draft invoice. A line item whose billed fields changed after it was pinned fails the
+// finalization with a conflict, the draft has to be voided and drafted again so that it bills the current amounts.
+func (s *InvoiceService) FinalizeInvoice(ctx context.Context, id string) (*domain.Invoice, error) {
+	return s.transitionInvoice(ctx, id, domain.InvoiceStatusFinalized, func(ctx context.Context, invoice *domain.Invoice) error {
+		for _, line := range invoice.Lines {
+			latest, err := s.paymentRepo.FindByID(ctx, line.PaymentLineItemID)
+			if err != nil {
+				return err
+			}
+			if !line.StillBills(latest, invoice.Currency) {
+				return apperror.Conflict(fmt.Sprintf("payment line item %s changed after it was added to the invoice", line.PaymentLineItemID))
+			}
+		}
+
+		number, err := s.repo.NextInvoiceNumber(ctx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		invoice.Number = &number
+		invoice.FinalizedAt = &now
+		return nil
+	})
+}
+
+// VoidInvoice voids a draft or finalized invoice and releases its line items for another invoice
+func (s *InvoiceService) VoidInvoice(ctx context.Context, id string) (*domain.Invoice, error) {
+	return s.transitionInvoice(ctx, id, domain.InvoiceStatusVoid, func(ctx context.Context, invoice *domain.Invoice) error {
+		now := time.Now().UTC()
+		invoice.VoidedAt = &now
+		return s.repo.ReleaseLines(ctx, id)
+	})
+}
+
+// transitionInvoice moves an invoice to status after apply ran, all in one transaction with the company locked
+func (s *InvoiceService) transitionInvoice(ctx context.Context, id string, status domain.InvoiceStatus, apply func(ctx context.Context, invoice *domain.Invoice) error) (*domain.Invoice, error) {
+	var invoice *domain.Invoice
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		invoice, err = s.GetInvoiceByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Finalizing and voiding the same invoice concurrently must not both succeed
+		if err := s.repo.LockCompany(ctx, invoice.CompanyID); err != nil {
+			return err
+		}
+		invoice, err = s.GetInvoiceByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := invoice.Status.ValidateTransition(status); err != nil {
+			return err
+		}
+		if err := apply(ctx, invoice); err != nil {
+			return err
+		}
+
+		invoice.Status = status
+		return s.repo.UpdatesByConditions(ctx, map[string]interface{}{"id": id}, map[string]interface{}{
+			"status":       invoice.Status,
+			"number":       invoice.Number,
+			"finalized_at": invoice.FinalizedAt,
+			"voided_at":    invoice.VoidedAt,
+			"updated_at":   time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
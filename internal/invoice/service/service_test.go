@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/invoice/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/mercor/payment-service/pkg/repository/static"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubCompanyRepo struct {
+	domain.CompanyRepository
+}
+
+func (stubCompanyRepo) GetByConditions(context.Context, map[string]interface{}) (*domain.Company, error) {
+	return &domain.Company{}, nil
+}
+
+// stubPaymentRepo keeps the latest version of each line item by id
+type stubPaymentRepo struct {
+	domain.PaymentLineRepository
+	latest map[string]*domain.PaymentLineItem
+	order  []string
+}
+
+func (r *stubPaymentRepo) put(id string, version int, item *domain.PaymentLineItem) {
+	item.SCDModel = &scd.SCDModel{ID: id, Version: version, UID: fmt.Sprintf("%s-v%d", id, version), IsLatest: true}
+	if _, ok := r.latest[id]; !ok {
+		r.order = append(r.order, id)
+	}
+	r.latest[id] = item
+}
+
+func (r *stubPaymentRepo) FindBillableByCompanyAndPeriod(context.Context, string, int64, int64, string) ([]domain.PaymentLineItem, error) {
+	items := make([]domain.PaymentLineItem, 0, len(r.order))
+	for _, id := range r.order {
+		items = append(items, *r.latest[id])
+	}
+	return items, nil
+}
+
+func (r *stubPaymentRepo) FindByID(_ context.Context, id string) (*domain.PaymentLineItem, error) {
+	item, ok := r.latest[id]
+	if !ok {
+		return nil, scd.ErrRecordNotFound
+	}
+	return item, nil
+}
+
+type stubInvoiceRepo struct {
+	domain.InvoiceRepository
+	invoices map[string]domain.Invoice
+	lines    map[string][]domain.InvoiceLine
+}
+
+func (r *stubInvoiceRepo) LockCompany(context.Context, string) error {
+	return nil
+}
+
+func (r *stubInvoiceRepo) Create(_ context.Context, invoice *domain.Invoice) error {
+	invoice.SetID(fmt.Sprintf("invoice-%d", len(r.invoices)+1))
+	r.invoices[invoice.GetID()] = *invoice
+	return nil
+}
+
+func (r *stubInvoiceRepo) CreateLines(_ context.Context, lines []*domain.InvoiceLine) error {
+	for _, line := range lines {
+		r.lines[line.InvoiceID] = append(r.lines[line.InvoiceID], *line)
+	}
+	return nil
+}
+
+func (r *stubInvoiceRepo) GetByConditions(_ context.Context, conditions map[string]interface{}) (*domain.Invoice, error) {
+	invoice, ok := r.invoices[conditions["id"].(string)]
+	if !ok {
+		return nil, static.ErrRecordNotFound
+	}
+	return &invoice, nil
+}
+
+func (r *stubInvoiceRepo) FindLines(_ context.Context, invoiceID string) ([]domain.InvoiceLine, error) {
+	return r.lines[invoiceID], nil
+}
+
+func (r *stubInvoiceRepo) NextInvoiceNumber(context.Context) (string, error) {
+	return "INV-000001", nil
+}
+
+func (r *stubInvoiceRepo) UpdatesByConditions(_ context.Context, conditions map[string]interface{}, updates map[string]interface{}) error {
+	id := conditions["id"].(string)
+	invoice := r.invoices[id]
+	invoice.Status = updates["status"].(domain.InvoiceStatus)
+	r.invoices[id] = invoice
+	return nil
+}
+
+func TestFinalizeInvoice(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(item *domain.PaymentLineItem)
+		wantErr bool
+	}{
+		{
+			name:   "should finalize when the pinned line item was approved since the draft",
+			change: func(item *domain.PaymentLineItem) { item.Status = domain.PaymentLineItemStatusApproved },
+		},
+		{
+			name:   "should finalize when the pinned line item was paid since the draft",
+			change: func(item *domain.PaymentLineItem) { item.Status = domain.PaymentLineItemStatusPaid },
+		},
+		{
+			name:    "should conflict when the pinned line item was voided since the draft",
+			change:  func(item *domain.PaymentLineItem) { item.Status = domain.PaymentLineItemStatusVoided },
+			wantErr: true,
+		},
+		{
+			name:    "should conflict when the amount of the pinned line item changed since the draft",
+			change:  func(item *domain.PaymentLineItem) { item.Amount = money.MustParse("75.00") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := &stubPaymentRepo{latest: map[string]*domain.PaymentLineItem{}}
+			paymentRepo.put("item-1", 1, domain.NewPaymentLineItem("job-v1", "timelog-v1", money.MustParse("50.00"), "USD", "USD", money.NewFromInt(1), domain.PaymentLineItemStatusPending))
+			invoiceRepo := &stubInvoiceRepo{invoices: map[string]domain.Invoice{}, lines: map[string][]domain.InvoiceLine{}}
+			svc := NewInvoiceService(invoiceRepo, paymentRepo, stubCompanyRepo{}, postgrestest.Transactor{})
+			ctx := context.Background()
+
+			draft, err := svc.DraftInvoice(ctx, &request.DraftInvoiceSvcReq{CompanyID: "company-1", TimeStart: 0, TimeEnd: 1000, Currency: "USD"})
+			assert.NoError(t, err)
+
+			next := *paymentRepo.latest["item-1"]
+			tt.change(&next)
+			paymentRepo.put("item-1", 2, &next)
+
+			invoice, err := svc.FinalizeInvoice(ctx, draft.GetID())
+
+			if tt.wantErr {
+				var appErr *apperror.Error
+				if assert.ErrorAs(t, err, &appErr) {
+					assert.Equal(t, apperror.CodeConflict, appErr.Code)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, domain.InvoiceStatusFinalized, invoice.Status)
+			assert.Equal(t, "INV-000001", *invoice.Number)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package request
+
+import "github.com/mercor/payment-service/pkg/money"
+
+// ListJobsSvcReq filters the latest job versions, empty fields match every job
+type ListJobsSvcReq struct {
+	Status       string
+	CompanyID    string
+	ContractorID string
+}
+
+// UpdateJobSvcReq replaces the fields of a job that can change, the company and contractor of a job are fixed
+type UpdateJobSvcReq struct {
+	Status   string        `json:"status"`
+	Rate     money.Decimal `json:"rate"`
+	Currency string        `json:"currency"`
+	Title    string        `json:"title"`
+}
+
+// PatchJobSvcReq carries only the fields of a job that should change
+type PatchJobSvcReq struct {
+	Status   *string
+	Rate     *money.Decimal
+	Currency *string
+	Title    *string
+}
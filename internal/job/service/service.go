@@ -2,16 +2,24 @@ package job
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/mercor/payment-service/internal/domain"
 	"github.com/mercor/payment-service/internal/job/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/mercor/payment-service/pkg/repository/static"
 )
 
 type Service struct {
 	jobRepo        domain.JobRepositoryInterface
 	companyRepo    domain.CompanyRepository
 	contractorRepo domain.ContractorRepository
+	tx             postgres.Transactor
 }
 
 var (
@@ -19,22 +27,31 @@ var (
 	svcOnce sync.Once
 )
 
-func NewService(repo domain.JobRepositoryInterface, companyRepo domain.CompanyRepository, contractorRepo domain.ContractorRepository) *Service {
+func NewService(repo domain.JobRepositoryInterface, companyRepo domain.CompanyRepository, contractorRepo domain.ContractorRepository, tx postgres.Transactor) *Service {
 	svcOnce.Do(func() {
-		svc = &Service{jobRepo: repo, companyRepo: companyRepo, contractorRepo: contractorRepo}
+		svc = &Service{jobRepo: repo, companyRepo: companyRepo, contractorRepo: contractorRepo, tx: tx}
 	})
 	return svc
 }
 
 func (s *Service) CreateJob(ctx context.Context, req *request.CreateJobSvcReq) error {
-	err := s.validateCreateJobRequest(ctx, req)
+	status, err := domain.ParseJobStatus(req.Status)
+	if err != nil {
+		return err
+	}
+	if !status.CanStartAs() {
+		return apperror.Validation(fmt.Sprintf("a job can't be created as %s", status))
+	}
+
+	err = s.validateCreateJobRequest(ctx, req)
 	if err != nil {
 		return err
 	}
 
 	j := domain.NewJob(
-		req.Status,
+		status,
 		req.Rate,
+		req.Currency,
 		req.Title,
 		req.CompanyID,
 		req.ContractorID,
@@ -44,21 +61,200 @@ func (s *Service) CreateJob(ctx context.Context, req *request.CreateJobSvcReq) e
 }
 
 func (s *Service) validateCreateJobRequest(ctx context.Context, req *request.CreateJobSvcReq) error {
-	_, err := s.companyRepo.GetByConditions(ctx, map[string]interface{}{"id": req.CompanyID})
+	return s.validateParties(ctx, req.CompanyID, req.ContractorID)
+}
+
+// GetJobByID returns the latest version of the job, or the given version when it's set
+func (s *Service) GetJobByID(ctx context.Context, id string, version *int) (*domain.Job, error) {
+	if version == nil {
+		return s.jobRepo.FindByID(ctx, id)
+	}
+
+	versions, err := s.jobRepo.FindVersionsForID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		if versions[i].GetVersion() == *version {
+			return &versions[i], nil
+		}
+	}
+	return nil, apperror.NotFound(fmt.Sprintf("job %s has no version %d", id, *version))
+}
+
+func (s *Service) GetJobs(ctx context.Context, req *request.ListJobsSvcReq, page pagination.Request) (*pagination.Page[domain.Job], error) {
+	filter := map[string]interface{}{}
+	if req.Status != "" {
+		status, err := domain.ParseJobStatus(req.Status)
+		if err != nil {
+			return nil, err
+		}
+		filter["status"] = string(status)
+	}
+	if req.CompanyID != "" {
+		filter["company_id"] = req.CompanyID
+	}
+	if req.ContractorID != "" {
+		filter["contractor_id"] = req.ContractorID
+	}
+	return s.jobRepo.FindLatestWithFilterPage(ctx, filter, page)
+}
+
+// UpdateJob writes a new version of the job with the fields of the request
+func (s *Service) UpdateJob(ctx context.Context, id string, expectedVersion *int, req *request.UpdateJobSvcReq) (*domain.Job, error) {
+	status, err := domain.ParseJobStatus(req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateJob(ctx, id, expectedVersion, func(job *domain.Job) {
+		job.Status = status
+		job.Rate = req.Rate
+		job.Currency = req.Currency
+		job.Title = req.Title
+	})
+}
+
+// PatchJob writes a new version of the job that changes only the fields set in the request
+func (s *Service) PatchJob(ctx context.Context, id string, expectedVersion *int, req *request.PatchJobSvcReq) (*domain.Job, error) {
+	var status *domain.JobStatus
+	if req.Status != nil {
+		parsed, err := domain.ParseJobStatus(*req.Status)
+		if err != nil {
+			return nil, err
+		}
+		status = &parsed
+	}
+
+	return s.updateJob(ctx, id, expectedVersion, func(job *domain.Job) {
+		if status != nil {
+			job.Status = *status
+		}
+		if req.Rate != nil {
+			job.Rate = *req.Rate
+		}
+		if req.Currency != nil {
+			job.Currency = *req.Currency
+		}
+		if req.Title != nil {
+			job.Title = *req.Title
+		}
+	})
+}
+
+// TransitionJob moves the job to status with a new version, moving a job to the status it's in is refused.
+// An expectedVersion makes the transition conditional on the latest version like the updates.
+func (s *Service) TransitionJob(ctx context.Context, id string, expectedVersion *int, status domain.JobStatus) (*domain.Job, error) {
+	latest, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && latest.GetVersion() != *expectedVersion {
+		return nil, scd.ErrVersionConflict
+	}
+	if latest.Status == status {
+		return nil, apperror.InvalidTransition(fmt.Sprintf("job is already %s", status))
+	}
+
+	version := latest.GetVersion()
+	return s.updateJob(ctx, id, &version, func(job *domain.Job) {
+		job.Status = status
+	})
+}
+
+// updateJob copies the latest version of the job, applies change to the copy and writes it as the next version,
+// guarded by the version the status transition was validated against
+func (s *Service) updateJob(ctx context.Context, id string, expectedVersion *int, change func(job *domain.Job)) (*domain.Job, error) {
+	latest, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && latest.GetVersion() != *expectedVersion {
+		return nil, scd.ErrVersionConflict
+	}
+
+	job := domain.NewJob(latest.Status, latest.Rate, latest.Currency, latest.Title, latest.CompanyID, latest.ContractorID)
+	change(job)
+
+	if err := latest.Status.ValidateTransition(job.Status); err != nil {
+		return nil, err
+	}
+	if job.Status.IsActive() && !latest.Status.IsActive() {
+		if err := s.validateParties(ctx, job.CompanyID, job.ContractorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.jobRepo.UpdateIfVersion(ctx, id, latest.GetVersion(), job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// validateParties refuses jobs whose company or contractor is gone, time could be logged against them otherwise
+func (s *Service) validateParties(ctx context.Context, companyID, contractorID string) error {
+	_, err := s.companyRepo.GetByConditions(ctx, map[string]interface{}{"id": companyID})
+	if errors.Is(err, static.ErrRecordNotFound) {
+		return apperror.Validation(fmt.Sprintf("company %s does not exist", companyID))
+	}
 	if err != nil {
 		return err
 	}
-	_, err = s.contractorRepo.GetByConditions(ctx, map[string]interface{}{"id": req.ContractorID})
+
+	contractor, err := s.contractorRepo.GetByConditions(ctx, map[string]interface{}{"id": contractorID})
+	if errors.Is(err, static.ErrRecordNotFound) {
+		return apperror.Validation(fmt.Sprintf("contractor %s does not exist", contractorID))
+	}
 	if err != nil {
 		return err
 	}
+	if !contractor.IsActive() {
+		return apperror.Validation(fmt.Sprintf("contractor %s is deactivated", contractorID))
+	}
 	return nil
 }
 
-func (s *Service) GetJobsByStatus(ctx context.Context, status string) ([]domain.Job, error) {
-	return s.jobRepo.FindLatestWithFilter(ctx, map[string]interface{}{"status": status})
+// GetJobHistory returns every version of a job with the fields each version changed
+func (s *Service) GetJobHistory(ctx context.Context, id string) ([]scd.VersionHistory, error) {
+	return s.jobRepo.FindHistoryForID(ctx, id)
 }
 
-func (s *Service) GetActiveJobsForContractor(ctx context.Context, contractorID string) ([]domain.Job, error) {
-	return s.jobRepo.FindLatestWithFilter(ctx, map[string]interface{}{"contractor_id": contractorID, "status": "active"})
+func (s *Service) GetActiveJobsForContractor(ctx context.Context, contractorID string, page pagination.Request) (*pagination.Page[domain.Job], error) {
+	return s.jobRepo.FindLatestWithFilterPage(ctx, map[string]interface{}{"contractor_id": contractorID, "status": domain.ActiveJobStatusFilter()}, page)
+}
+
+// RevertJobToVersion creates a new version of the job that copies the given earlier version,
+// the status of that version must be reachable from the latest status. The revert is guarded by the latest version
+// the status was checked against, a write in between fails it with a conflict.
+func (s *Service) RevertJobToVersion(ctx context.Context, id string, version int) (*domain.Job, error) {
+	var reverted *domain.Job
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		versions, err := s.jobRepo.FindVersionsForID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		var latest, target *domain.Job
+		for i := range versions {
+			if versions[i].GetIsLatest() {
+				latest = &versions[i]
+			}
+			if versions[i].GetVersion() == version {
+				target = &versions[i]
+			}
+		}
+		if latest == nil || target == nil {
+			return scd.ErrRecordNotFound
+		}
+		if err := latest.Status.ValidateTransition(target.Status); err != nil {
+			return err
+		}
+
+		reverted, err = s.jobRepo.RevertToVersionIfVersion(ctx, id, version, latest.GetVersion())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reverted, nil
 }
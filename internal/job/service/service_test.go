@@ -0,0 +1,147 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/job/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubJobRepo keeps the latest version of job-1, raced bumps that version between the read and the write
+// like a concurrent update would
+type stubJobRepo struct {
+	domain.JobRepositoryInterface
+	latest  *domain.Job
+	raced   bool
+	written *domain.Job
+}
+
+func (r *stubJobRepo) FindByID(context.Context, string) (*domain.Job, error) {
+	latest := *r.latest
+	return &latest, nil
+}
+
+func (r *stubJobRepo) UpdateIfVersion(_ context.Context, id string, expectedVersion int, job *domain.Job) error {
+	if r.raced {
+		r.latest.Version++
+	}
+	if r.latest.GetVersion() != expectedVersion {
+		return scd.ErrVersionConflict
+	}
+	version := expectedVersion + 1
+	job.SCDModel = &scd.SCDModel{ID: id, Version: version, UID: fmt.Sprintf("%s-v%d", id, version), IsLatest: true}
+	r.written = job
+	return nil
+}
+
+func newTestService(status domain.JobStatus, raced bool) (*Service, *stubJobRepo) {
+	latest := domain.NewJob(status, money.NewFromInt(10), "USD", "Engineer", "company-1", "contractor-1")
+	latest.SCDModel = &scd.SCDModel{ID: "job-1", Version: 3, UID: "job-1-v3", IsLatest: true}
+	repo := &stubJobRepo{latest: latest, raced: raced}
+	// NewService keeps the first service it built, each test needs its own repository
+	return &Service{jobRepo: repo}, repo
+}
+
+func TestTransitionJob(t *testing.T) {
+	ctx := context.Background()
+	version := func(v int) *int { return &v }
+
+	tests := []struct {
+		name            string
+		expectedVersion *int
+		raced           bool
+		status          domain.JobStatus
+		wantErr         error
+		wantCode        apperror.Code
+	}{
+		{
+			name:   "should write the next version",
+			status: domain.JobStatusEnded,
+		},
+		{
+			name:            "should write the next version when the expected version is the latest",
+			expectedVersion: version(3),
+			status:          domain.JobStatusPaused,
+		},
+		{
+			name:            "should conflict when the expected version is not the latest",
+			expectedVersion: version(2),
+			status:          domain.JobStatusEnded,
+			wantErr:         scd.ErrVersionConflict,
+		},
+		{
+			name:    "should conflict when the job changed after its status was checked",
+			raced:   true,
+			status:  domain.JobStatusEnded,
+			wantErr: scd.ErrVersionConflict,
+		},
+		{
+			name:     "should refuse moving the job to its status",
+			status:   domain.JobStatusActive,
+			wantCode: apperror.CodeInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(domain.JobStatusActive, tt.raced)
+
+			job, err := svc.TransitionJob(ctx, "job-1", tt.expectedVersion, tt.status)
+
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, repo.written)
+			case tt.wantCode != "":
+				assert.True(t, apperror.HasCode(err, tt.wantCode), err)
+				assert.Nil(t, repo.written)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.status, job.Status)
+				assert.Equal(t, 4, job.GetVersion())
+			}
+		})
+	}
+}
+
+func TestUpdateJob(t *testing.T) {
+	ctx := context.Background()
+	req := &request.UpdateJobSvcReq{Status: string(domain.JobStatusActive), Rate: money.NewFromInt(20), Currency: "USD", Title: "Lead engineer"}
+
+	t.Run("should write the next version with the fields of the request", func(t *testing.T) {
+		svc, repo := newTestService(domain.JobStatusActive, false)
+		expected := 3
+
+		job, err := svc.UpdateJob(ctx, "job-1", &expected, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, job.GetVersion())
+		assert.Equal(t, money.NewFromInt(20), repo.written.Rate)
+		assert.Equal(t, "Lead engineer", repo.written.Title)
+	})
+
+	t.Run("should conflict when the expected version is not the latest", func(t *testing.T) {
+		svc, repo := newTestService(domain.JobStatusActive, false)
+		expected := 2
+
+		_, err := svc.UpdateJob(ctx, "job-1", &expected, req)
+
+		assert.ErrorIs(t, err, scd.ErrVersionConflict)
+		assert.Nil(t, repo.written)
+	})
+
+	t.Run("should conflict when the job changed after it was read", func(t *testing.T) {
+		svc, repo := newTestService(domain.JobStatusActive, true)
+
+		_, err := svc.UpdateJob(ctx, "job-1", nil, req)
+
+		assert.ErrorIs(t, err, scd.ErrVersionConflict)
+		assert.Nil(t, repo.written)
+	})
+}
@@ -3,19 +3,19 @@ package middlewares
 import (
 	"context"
 	"errors"
-	"fmt"
-	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
-
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/mercor/payment-service/constants"
+	"github.com/mercor/payment-service/pkg/apperror"
 	"github.com/mercor/payment-service/pkg/config"
+	"github.com/mercor/payment-service/pkg/jwks"
 	"github.com/mercor/payment-service/pkg/log"
-	jwt "github.com/dgrijalva/jwt-go"
-	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
 func AuthenticateJWT(ctx context.Context) gin.HandlerFunc {
@@ -24,32 +24,23 @@ func AuthenticateJWT(ctx context.Context) gin.HandlerFunc {
 		if err != nil {
 			log.Errorf("Not able to extract token from header")
 			log.Error(err)
-			c.AbortWithStatus(http.StatusUnauthorized)
+			apperror.Abort(c, apperror.Unauthorized("missing bearer token"))
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(bearerToken, &JWTClaim{}, func(token *jwt.Token) (interface{}, error) {
-			rsaKey, cusErr := getRSAPublicKey(c, config.GetString(c, "authentication.rsaPublicKey"))
-			if cusErr != nil {
-				return nil, cusErr
-			}
-
-			return rsaKey, cusErr
-		})
+		verifier, err := verifiers.get(verifierSettingsFromConfig(c))
 		if err != nil {
-			c.AbortWithStatus(http.StatusUnauthorized)
+			apperror.Abort(c, apperror.Unauthorized("invalid token"))
 			return
 		}
 
-		if !token.Valid {
-			log.Errorf("Token is invalid :: %v", token)
-			log.Error(err)
-			c.AbortWithStatus(http.StatusUnauthorized)
+		claims := &JWTClaim{}
+		if err := verifier.Verify(c, bearerToken, claims); err != nil {
+			log.Errorf("Token is invalid :: %v", err)
+			apperror.Abort(c, apperror.Unauthorized("invalid token"))
 			return
 		}
 
-		claims := token.Claims.(*JWTClaim)
-
 		c.Set(constants.UserDetails, &claims.UserDetails)
 		c.Next()
 	}
@@ -64,34 +55,147 @@ func extractToken(c *gin.Context) (string, error) {
 }
 
 type JWTClaim struct {
-	jwt.StandardClaims
+	jwt.RegisteredClaims
 	UserDetails UserDetails
 }
 
 type UserDetails struct {
 	ID    string
 	Email string
+	Roles []Role
 }
 
-func getRSAPublicKey(ctx context.Context, publicKey string) (rsaPubKey *rsa.PublicKey, err error) {
-	pubPem, _ := pem.Decode([]byte(publicKey))
+// GetUserDetails returns the details of the authenticated user stored by AuthenticateJWT
+func GetUserDetails(c *gin.Context) (*UserDetails, bool) {
+	value, exists := c.Get(constants.UserDetails)
+	if !exists {
+		return nil, false
+	}
 
-	if pubPem.Type != "PUBLIC KEY" {
-		err = errors.New(fmt.Sprintf("RSA public key is of the wrong type, Pem Type :%s", pubPem.Type))
-		return
+	userDetails, ok := value.(*UserDetails)
+	return userDetails, ok
+}
+
+// WithChangeInfo returns a context that stamps the authenticated user and reason on the SCD versions written with it
+func WithChangeInfo(c *gin.Context, reason string) context.Context {
+	var changedBy string
+	if userDetails, ok := GetUserDetails(c); ok {
+		changedBy = userDetails.ID
 	}
+	return scd.WithChangeInfo(c, changedBy, reason)
+}
 
-	parsedKey, parseErr := x509.ParsePKIXPublicKey(pubPem.Bytes)
-	if parseErr != nil {
-		err = errors.New(fmt.Sprintf("RSA public key is of the wrong type, Pem Type :%s", pubPem.Type))
-		return
+// verifierSettings are the authentication settings a verifier is built from
+type verifierSettings struct {
+	source    string
+	refresh   time.Duration
+	issuers   []string
+	audiences []string
+	leeway    time.Duration
+}
+
+func verifierSettingsFromConfig(ctx context.Context) verifierSettings {
+	return verifierSettings{
+		source:    config.GetString(ctx, "authentication.jwks.source"),
+		refresh:   config.GetDuration(ctx, "authentication.jwks.refreshInterval"),
+		issuers:   config.GetStringSlice(ctx, "authentication.issuers"),
+		audiences: config.GetStringSlice(ctx, "authentication.audiences"),
+		leeway:    config.GetDuration(ctx, "authentication.leeway"),
 	}
+}
+
+func (s verifierSettings) equal(other verifierSettings) bool {
+	return s.source == other.source && s.refresh == other.refresh && s.leeway == other.leeway &&
+		slices.Equal(s.issuers, other.issuers) && slices.Equal(s.audiences, other.audiences)
+}
+
+const (
+	// minVerifierRetry and maxVerifierRetry bound how long a failed verifier build is served before it is tried again
+	minVerifierRetry = time.Second
+	maxVerifierRetry = time.Minute
+)
+
+// verifierCache keeps the verifier built from the configured settings, it builds another once the config changes
+// so the cached keys of the JWKS survive between requests. A failed build is retried once its backoff elapses,
+// the backoff doubles while the same settings keep failing.
+type verifierCache struct {
+	build func(verifierSettings) (*jwks.Verifier, error)
+	now   func() time.Time
+
+	mu       sync.RWMutex
+	settings *verifierSettings
+	verifier *jwks.Verifier
+	err      error
+	retryAt  time.Time
+	backoff  time.Duration
+}
 
-	rsaPubKey, ok := parsedKey.(*rsa.PublicKey)
-	if !ok {
-		err = errors.New("unable to parse pub key")
-		return
+var verifiers = newVerifierCache(newVerifier)
+
+func newVerifierCache(build func(verifierSettings) (*jwks.Verifier, error)) *verifierCache {
+	return &verifierCache{build: build, now: time.Now}
+}
+
+func (v *verifierCache) get(settings verifierSettings) (*jwks.Verifier, error) {
+	v.mu.RLock()
+	if v.usable(settings) {
+		defer v.mu.RUnlock()
+		return v.verifier, v.err
+	}
+	v.mu.RUnlock()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	// Another request may have built the verifier meanwhile
+	if v.usable(settings) {
+		return v.verifier, v.err
+	}
+
+	verifier, err := v.build(settings)
+	if err != nil {
+		log.Errorf("Unable to build the token verifier :: %v", err)
+		if v.err != nil && v.settings != nil && v.settings.equal(settings) {
+			v.backoff = min(2*v.backoff, maxVerifierRetry)
+		} else {
+			v.backoff = minVerifierRetry
+		}
+		v.retryAt = v.now().Add(v.backoff)
+	}
+	// The replaced verifier must stop refreshing its keys
+	if v.verifier != nil {
+		v.verifier.Close()
 	}
+	v.settings, v.verifier, v.err = &settings, verifier, err
+	return verifier, err
+}
 
-	return
+// usable tells whether the verifier or error kept for settings may be served, instead of building another
+func (v *verifierCache) usable(settings verifierSettings) bool {
+	if v.settings == nil || !v.settings.equal(settings) {
+		return false
+	}
+	return v.err == nil || v.now().Before(v.retryAt)
+}
+
+func newVerifier(settings verifierSettings) (*jwks.Verifier, error) {
+	keys, err := jwks.NewKeySet(settings.source, settings.refresh)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := jwks.NewVerifier(keys, settings.issuers, settings.audiences, settings.leeway)
+	if err != nil {
+		return nil, err
+	}
+	keys.StartRefresh()
+	return verifier, nil
+}
+
+// LoadVerifier builds the token verifier from the config and reads its keys, so the server refuses to start
+// with a JWKS source that cannot be read or holds no key instead of rejecting every token
+func LoadVerifier(ctx context.Context) error {
+	verifier, err := verifiers.get(verifierSettingsFromConfig(ctx))
+	if err != nil {
+		return err
+	}
+	return verifier.Keys().Refresh(ctx)
 }
@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mercor/payment-service/pkg/jwks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifierCache(t *testing.T) {
+	settings := verifierSettings{source: "./jwks.json", issuers: []string{"issuer"}, audiences: []string{"payment-service"}}
+	errBuild := errors.New("no source configured")
+
+	// newCache fails as many builds as failures before it succeeds, the test moves its clock
+	newCache := func(failures int) (*verifierCache, *int, *time.Time) {
+		builds := 0
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		cache := newVerifierCache(func(verifierSettings) (*jwks.Verifier, error) {
+			builds++
+			if builds <= failures {
+				return nil, errBuild
+			}
+			return &jwks.Verifier{}, nil
+		})
+		cache.now = func() time.Time { return now }
+		return cache, &builds, &now
+	}
+
+	t.Run("should keep the verifier of unchanged settings", func(t *testing.T) {
+		cache, builds, _ := newCache(0)
+
+		first, err := cache.get(settings)
+		assert.NoError(t, err)
+		second, err := cache.get(settings)
+		assert.NoError(t, err)
+
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, *builds)
+	})
+
+	t.Run("should build another verifier once the settings change", func(t *testing.T) {
+		cache, builds, _ := newCache(0)
+		changed := settings
+		changed.leeway = time.Minute
+
+		_, _ = cache.get(settings)
+		_, err := cache.get(changed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, *builds)
+	})
+
+	t.Run("should retry a failed build once its backoff elapses", func(t *testing.T) {
+		cache, builds, now := newCache(1)
+
+		_, err := cache.get(settings)
+		assert.ErrorIs(t, err, errBuild)
+
+		*now = now.Add(minVerifierRetry - time.Millisecond)
+		_, err = cache.get(settings)
+		assert.ErrorIs(t, err, errBuild)
+		assert.Equal(t, 1, *builds)
+
+		*now = now.Add(time.Millisecond)
+		verifier, err := cache.get(settings)
+		assert.NoError(t, err)
+		assert.NotNil(t, verifier)
+		assert.Equal(t, 2, *builds)
+	})
+
+	t.Run("should double the backoff while the build keeps failing", func(t *testing.T) {
+		cache, builds, now := newCache(10)
+		backoffs := []time.Duration{}
+
+		for *builds < 10 {
+			_, err := cache.get(settings)
+			assert.ErrorIs(t, err, errBuild)
+			backoffs = append(backoffs, cache.backoff)
+			*now = cache.retryAt
+		}
+
+		assert.Equal(t, []time.Duration{
+			time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second,
+			32 * time.Second, time.Minute, time.Minute, time.Minute, time.Minute,
+		}, backoffs)
+	})
+
+	t.Run("should build at once for changed settings while a failure is backing off", func(t *testing.T) {
+		cache, builds, _ := newCache(1)
+		changed := settings
+		changed.source = "./other.json"
+
+		_, _ = cache.get(settings)
+		_, err := cache.get(changed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, *builds)
+	})
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/pkg/apperror"
+)
+
+// Role is what a user may do, tokens carry the roles of their user
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleCompany    Role = "company"
+	RoleContractor Role = "contractor"
+)
+
+// HasRole reports whether the user has any of the roles
+func (u *UserDetails) HasRole(roles ...Role) bool {
+	for _, have := range u.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RequireRoles lets a request through when the authenticated user has any of the roles, it must run after AuthenticateJWT
+func RequireRoles(roles ...Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userDetails, ok := GetUserDetails(c)
+		if !ok {
+			apperror.Abort(c, apperror.Unauthorized("authentication required"))
+			return
+		}
+
+		if !userDetails.HasRole(roles...) {
+			apperror.Abort(c, apperror.Forbidden(fmt.Sprintf("one of the roles %v is required", roles)))
+			return
+		}
+		c.Next()
+	}
+}
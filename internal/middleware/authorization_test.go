@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/constants"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *UserDetails
+		roles    []Role
+		wantCode int
+		wantErr  apperror.Code
+	}{
+		{
+			name:     "should let a user with the only role through",
+			user:     &UserDetails{ID: "admin-1", Roles: []Role{RoleAdmin}},
+			roles:    []Role{RoleAdmin},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should let a user with any of the roles through",
+			user:     &UserDetails{ID: "company-1", Roles: []Role{RoleCompany}},
+			roles:    []Role{RoleAdmin, RoleCompany},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should let a user with several roles through on one of them",
+			user:     &UserDetails{ID: "contractor-1", Roles: []Role{RoleCompany, RoleContractor}},
+			roles:    []Role{RoleContractor},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "should forbid a user without any of the roles",
+			user:     &UserDetails{ID: "contractor-1", Roles: []Role{RoleContractor}},
+			roles:    []Role{RoleAdmin, RoleCompany},
+			wantCode: http.StatusForbidden,
+			wantErr:  apperror.CodeForbidden,
+		},
+		{
+			name:     "should forbid a user without roles",
+			user:     &UserDetails{ID: "user-1"},
+			roles:    []Role{RoleAdmin},
+			wantCode: http.StatusForbidden,
+			wantErr:  apperror.CodeForbidden,
+		},
+		{
+			name:     "should refuse a request without user details",
+			roles:    []Role{RoleAdmin},
+			wantCode: http.StatusUnauthorized,
+			wantErr:  apperror.CodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			engine := gin.New()
+			engine.Use(apperror.Middleware())
+			engine.Use(func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set(constants.UserDetails, tt.user)
+				}
+			})
+			reached := false
+			engine.GET("/", RequireRoles(tt.roles...), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.wantCode, recorder.Code)
+			assert.Equal(t, tt.wantErr == "", reached)
+			if tt.wantErr != "" {
+				var response apperror.Response
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantErr, response.Code)
+			}
+		})
+	}
+}
@@ -2,14 +2,24 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/mercor/payment-service/internal/domain"
 	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/scd"
 	"gorm.io/gorm"
 )
 
+// earningsGroupKeys are the SQL expressions of the earnings group keys, days and weeks start at UTC midnight
+var earningsGroupKeys = map[domain.EarningsGroupBy]string{
+	domain.EarningsGroupByJob:    "job.id",
+	domain.EarningsGroupByDay:    "to_char(date_trunc('day', to_timestamp(timelog.time_start / 1000.0) AT TIME ZONE 'UTC'), 'YYYY-MM-DD')",
+	domain.EarningsGroupByWeek:   "to_char(date_trunc('week', to_timestamp(timelog.time_start / 1000.0) AT TIME ZONE 'UTC'), 'YYYY-MM-DD')",
+	domain.EarningsGroupByStatus: "payment_line_items.status",
+}
+
 var (
 	repo     *PaymentRepository
 	repoOnce sync.Once
@@ -32,14 +42,102 @@ func NewPaymentRepository(db *postgres.DbCluster) domain.PaymentLineRepository {
 }
 
 func (r *PaymentRepository) FindByContractorAndPeriod(ctx context.Context, contractorID string, startTime, endTime int64) ([]domain.PaymentLineItem, error) {
-	paymentItems, err := r.CustomQuery(ctx, func(db *gorm.DB) *gorm.DB {
+	paymentItems, err := r.CustomQuery(ctx, byContractorAndPeriod(contractorID, startTime, endTime))
+	if err != nil {
+		return nil, err
+	}
+	return paymentItems, nil
+}
+
+func (r *PaymentRepository) FindByContractorAndPeriodPage(ctx context.Context, contractorID string, startTime, endTime int64, page pagination.Request) (*pagination.Page[domain.PaymentLineItem], error) {
+	return r.CustomQueryPage(ctx, byContractorAndPeriod(contractorID, startTime, endTime), page)
+}
+
+// SumEarningsByContractorAndPeriod adds up the durations and amounts of the latest line items of the contractor
+// for the period that are not voided, per group and currency
+func (r *PaymentRepository) SumEarningsByContractorAndPeriod(ctx context.Context, contractorID string, startTime, endTime int64, groupBy domain.EarningsGroupBy) ([]domain.Earnings, error) {
+	key, ok := earningsGroupKeys[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown earnings group %q", groupBy)
+	}
+
+	var earnings []domain.Earnings
+	err := r.CustomScan(ctx, func(db *gorm.DB) *gorm.DB {
+		return byContractorAndPeriod(contractorID, startTime, endTime)(db).
+			Where("payment_line_items.status <> ?", domain.PaymentLineItemStatusVoided).
+			Select(key + " AS key, payment_line_items.currency, payment_line_items.payout_currency, " +
+				"SUM(timelog.duration) AS duration, SUM(payment_line_items.amount) AS amount, " +
+				"SUM(ROUND(payment_line_items.amount * payment_line_items.fx_rate, 2)) AS payout_amount, " +
+				"COUNT(*) AS line_items").
+			Group(key + ", payment_line_items.currency, payment_line_items.payout_currency").
+			Order("key, payment_line_items.currency, payment_line_items.payout_currency")
+	}, &earnings)
+	if err != nil {
+		return nil, err
+	}
+	return earnings, nil
+}
+
+// FindPayableByContractorAndPeriod returns the latest approved line items of the contractor for the period
+// that are paid out in the given currency and are not part of a payout yet
+func (r *PaymentRepository) FindPayableByContractorAndPeriod(ctx context.Context, contractorID string, startTime, endTime int64, payoutCurrency string) ([]domain.PaymentLineItem, error) {
+	return r.CustomQuery(ctx, func(db *gorm.DB) *gorm.DB {
+		return byContractorAndPeriod(contractorID, startTime, endTime)(db).
+			Where("payment_line_items.status = ? AND payment_line_items.payout_currency = ?", domain.PaymentLineItemStatusApproved, payoutCurrency).
+			Where("NOT EXISTS (SELECT 1 FROM payout_item WHERE payout_item.payment_line_item_id = payment_line_items.id AND NOT payout_item.released)")
+	})
+}
+
+// FindBillableByCompanyAndPeriod returns the latest line items of the company's jobs for the period in the given
+// currency that are not voided and not billed by an invoice yet
+func (r *PaymentRepository) FindBillableByCompanyAndPeriod(ctx context.Context, companyID string, startTime, endTime int64, currency string) ([]domain.PaymentLineItem, error) {
+	return r.CustomQuery(ctx, func(db *gorm.DB) *gorm.DB {
 		return db.
 			Joins("JOIN job ON job.uid = payment_line_items.job_uid").
 			Joins("JOIN timelog ON timelog.uid = payment_line_items.timelog_uid").
-			Where("job.contractor_id = ? AND timelog.time_start > ? AND timelog.time_end < ?", contractorID, startTime, endTime)
+			Where("job.company_id = ? AND timelog.time_start > ? AND timelog.time_end < ?", companyID, startTime, endTime).
+			Where("payment_line_items.status <> ? AND payment_line_items.currency = ?", domain.PaymentLineItemStatusVoided, currency).
+			Where("NOT EXISTS (SELECT 1 FROM invoice_line WHERE invoice_line.payment_line_item_id = payment_line_items.id AND NOT invoice_line.released)")
+	})
+}
+
+// FindLatestByTimelogID returns the latest line item generated from any version of the timelog
+func (r *PaymentRepository) FindLatestByTimelogID(ctx context.Context, timelogID string) (*domain.PaymentLineItem, error) {
+	paymentItems, err := r.CustomQuery(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("JOIN timelog ON timelog.uid = payment_line_items.timelog_uid").
+			Where("timelog.id = ?", timelogID).
+			Order("payment_line_items.valid_from DESC").
+			Limit(1)
 	})
 	if err != nil {
 		return nil, err
 	}
-	return paymentItems, nil
+	if len(paymentItems) == 0 {
+		return nil, scd.ErrRecordNotFound
+	}
+	return &paymentItems[0], nil
+}
+
+// IsPinned reports whether a payout or an invoice holds the line item and hasn't released it
+func (r *PaymentRepository) IsPinned(ctx context.Context, id string) (bool, error) {
+	var pinned bool
+	err := r.db.GetMasterDB(ctx).Raw(
+		"SELECT EXISTS (SELECT 1 FROM payout_item WHERE payment_line_item_id = ? AND NOT released) "+
+			"OR EXISTS (SELECT 1 FROM invoice_line WHERE payment_line_item_id = ? AND NOT released)",
+		id, id,
+	).Scan(&pinned).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether line item %s is pinned: %w", id, err)
+	}
+	return pinned, nil
+}
+
+func byContractorAndPeriod(contractorID string, startTime, endTime int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("JOIN job ON job.uid = payment_line_items.job_uid").
+			Joins("JOIN timelog ON timelog.uid = payment_line_items.timelog_uid").
+			Where("job.contractor_id = ? AND timelog.time_start > ? AND timelog.time_end < ?", contractorID, startTime, endTime)
+	}
 }
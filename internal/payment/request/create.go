@@ -1,11 +1,13 @@
 package request
 
+import "github.com/mercor/payment-service/pkg/money"
+
 // Add payment service request structs here as needed.
 
 // CreatePaymentRequest represents a request to create a new payment
 type CreatePaymentRequest struct {
-	ContractorID string  `json:"contractor_id"`
-	Amount       float64 `json:"amount"`
-	Description  string  `json:"description"`
-	Date         string  `json:"date"`
+	ContractorID string        `json:"contractor_id"`
+	Amount       money.Decimal `json:"amount"`
+	Description  string        `json:"description"`
+	Date         string        `json:"date"`
 }
@@ -0,0 +1,14 @@
+package request
+
+import "github.com/mercor/payment-service/pkg/money"
+
+// UpdatePaymentLineItemSvcReq carries every column of a new payment line item version
+type UpdatePaymentLineItemSvcReq struct {
+	JobUID         string        `json:"job_uid"`
+	TimelogUID     string        `json:"timelog_uid"`
+	Amount         money.Decimal `json:"amount"`
+	Currency       string        `json:"currency"`
+	PayoutCurrency string        `json:"payout_currency"`
+	FxRate         money.Decimal `json:"fx_rate"`
+	Status         string        `json:"status"`
+}
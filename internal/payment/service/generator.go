@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/fx"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+)
+
+// LineItemGenerator creates the payment line item of a timelog and re-versions it when the timelog changes
+type LineItemGenerator struct {
+	repo           domain.PaymentLineRepository
+	jobRepo        domain.JobRepositoryInterface
+	contractorRepo domain.ContractorRepository
+	fxProvider     fx.Provider
+}
+
+func NewLineItemGenerator(
+	repo domain.PaymentLineRepository,
+	jobRepo domain.JobRepositoryInterface,
+	contractorRepo domain.ContractorRepository,
+	fxProvider fx.Provider,
+) *LineItemGenerator {
+	return &LineItemGenerator{repo: repo, jobRepo: jobRepo, contractorRepo: contractorRepo, fxProvider: fxProvider}
+}
+
+// GenerateForTimelog prices the timelog with the rate of the job version it points to and records the rate
+// to the contractor's payout currency. A line item is created for a newly approved timelog, the existing one gets
+// a new version guarded by its current version when the timelog version, job version, amount or currencies differ.
+// A new amount, currency or rate sets the line item back to pending, a line item that is final or held by a payout
+// or an invoice can't change.
+// Timelogs that are not approved get no line item, the line item of a timelog that lost its approval is voided.
+func (g *LineItemGenerator) GenerateForTimelog(ctx context.Context, timelog *domain.Timelog) (*domain.PaymentLineItem, error) {
+	if !timelog.IsApproved() {
+		return g.voidForTimelog(ctx, timelog)
+	}
+
+	job, err := g.jobRepo.FindByUID(ctx, timelog.JobUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job version %s of timelog %s: %w", timelog.JobUID, timelog.GetID(), err)
+	}
+	amount, err := lineItemAmount(timelog.Duration, job.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	contractor, err := g.contractorRepo.GetByConditions(ctx, map[string]interface{}{"id": job.ContractorID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find contractor %s of job %s: %w", job.ContractorID, job.GetID(), err)
+	}
+
+	existing, err := g.repo.FindLatestByTimelogID(ctx, timelog.GetID())
+	if err != nil && !errors.Is(err, scd.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if existing != nil &&
+		existing.TimelogUID == timelog.GetUID() &&
+		existing.JobUID == job.UID &&
+		existing.Amount == amount &&
+		existing.Currency == job.Currency &&
+		existing.PayoutCurrency == contractor.PayoutCurrency {
+		return existing, nil
+	}
+
+	if existing != nil {
+		if existing.Status.IsFinal() {
+			return nil, apperror.InvalidTransition(fmt.Sprintf("payment line item %s of timelog %s is %s and can no longer change", existing.GetID(), timelog.GetID(), existing.Status))
+		}
+		pinned, err := g.repo.IsPinned(ctx, existing.GetID())
+		if err != nil {
+			return nil, err
+		}
+		if pinned {
+			return nil, apperror.Conflict(fmt.Sprintf("payment line item %s of timelog %s is in a payout or on an invoice and can't change until it is released", existing.GetID(), timelog.GetID()))
+		}
+	}
+
+	fxRate, err := g.fxProvider.Rate(ctx, job.Currency, contractor.PayoutCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		paymentLineItem := domain.NewPaymentLineItem(
+			job.UID,
+			timelog.GetUID(),
+			amount,
+			job.Currency,
+			contractor.PayoutCurrency,
+			fxRate,
+			domain.PaymentLineItemStatusPending,
+		)
+		if err := g.repo.Create(ctx, paymentLineItem); err != nil {
+			return nil, err
+		}
+		return paymentLineItem, nil
+	}
+
+	changes := map[string]interface{}{
+		"job_uid":         job.UID,
+		"timelog_uid":     timelog.GetUID(),
+		"amount":          amount,
+		"currency":        job.Currency,
+		"payout_currency": contractor.PayoutCurrency,
+		"fx_rate":         fxRate,
+	}
+	// A new price withdraws the approval of the old one
+	if existing.Amount != amount ||
+		existing.Currency != job.Currency ||
+		existing.PayoutCurrency != contractor.PayoutCurrency ||
+		existing.FxRate != fxRate {
+		changes["status"] = domain.PaymentLineItemStatusPending
+	}
+	return g.repo.PatchIfVersion(ctx, existing.GetID(), existing.GetVersion(), changes)
+}
+
+// ApproveForTimelog generates the line item of an approved timelog and approves it when it is pending, which makes it
+// payable. The approval is a new line item version guarded by the version that was generated.
+func (g *LineItemGenerator) ApproveForTimelog(ctx context.Context, timelog *domain.Timelog) (*domain.PaymentLineItem, error) {
+	if !timelog.IsApproved() {
+		return nil, apperror.InvalidTransition(fmt.Sprintf("timelog %s is %s, only the line item of an approved timelog can be approved", timelog.GetID(), timelog.ApprovalStatus))
+	}
+
+	item, err := g.GenerateForTimelog(ctx, timelog)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != domain.PaymentLineItemStatusPending {
+		return item, nil
+	}
+
+	return g.repo.PatchIfVersion(ctx, item.GetID(), item.GetVersion(), map[string]interface{}{
+		"status": domain.PaymentLineItemStatusApproved,
+	})
+}
+
+// voidForTimelog voids the line item of a timelog that is not approved, it returns nil when there is none
+func (g *LineItemGenerator) voidForTimelog(ctx context.Context, timelog *domain.Timelog) (*domain.PaymentLineItem, error) {
+	existing, err := g.repo.FindLatestByTimelogID(ctx, timelog.GetID())
+	if errors.Is(err, scd.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing.Status == domain.PaymentLineItemStatusVoided {
+		return existing, nil
+	}
+	if err := existing.Status.ValidateTransition(domain.PaymentLineItemStatusVoided); err != nil {
+		return nil, apperror.InvalidTransition(fmt.Sprintf("payment line item %s of timelog %s is %s and can no longer be voided", existing.GetID(), timelog.GetID(), existing.Status))
+	}
+
+	return g.repo.PatchIfVersion(ctx, existing.GetID(), existing.GetVersion(), map[string]interface{}{
+		"timelog_uid": timelog.GetUID(),
+		"status":      domain.PaymentLineItemStatusVoided,
+	})
+}
+
+// lineItemAmount is the hourly rate times the hours logged, rounded half up to cents once
+func lineItemAmount(duration int64, rate money.Decimal) (money.Decimal, error) {
+	return rate.MulRatio(duration, int64(time.Hour/time.Millisecond), money.CentPlaces, money.RoundHalfUp)
+}
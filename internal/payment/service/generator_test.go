@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineItemAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		rate     string
+		want     string
+	}{
+		{name: "should bill the rate for an hour", duration: time.Hour, rate: "10", want: "10.00"},
+		{name: "should bill a fraction of the rate for part of an hour", duration: 90 * time.Minute, rate: "25.50", want: "38.25"},
+		{name: "should bill a sub-minute duration", duration: 30 * time.Second, rate: "60", want: "0.50"},
+		{name: "should round a half cent up", duration: 30 * time.Minute, rate: "0.09", want: "0.05"},
+		{name: "should round below a half cent down", duration: time.Second, rate: "10", want: "0.00"},
+		{name: "should round once instead of per minute", duration: 61 * time.Second, rate: "0.59", want: "0.01"},
+		{name: "should bill nothing for no time", duration: 0, rate: "100", want: "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := lineItemAmount(tt.duration.Milliseconds(), money.MustParse(tt.rate))
+
+			assert.NoError(t, err)
+			assert.Equal(t, money.MustParse(tt.want), amount)
+		})
+	}
+}
+
+// stubJobRepo serves version 1 of job-1 at the rate
+type stubJobRepo struct {
+	domain.JobRepositoryInterface
+	rate money.Decimal
+}
+
+func (r stubJobRepo) FindByUID(_ context.Context, uid string) (*domain.Job, error) {
+	job := domain.NewJob("active", r.rate, "USD", "Engineer", "company-1", "contractor-1")
+	job.SCDModel = &scd.SCDModel{ID: "job-1", Version: 1, UID: uid, IsLatest: true}
+	return job, nil
+}
+
+type stubContractorRepo struct {
+	domain.ContractorRepository
+}
+
+func (stubContractorRepo) GetByConditions(context.Context, map[string]interface{}) (*domain.Contractor, error) {
+	return &domain.Contractor{PayoutCurrency: "EUR"}, nil
+}
+
+type stubFxProvider struct{}
+
+func (stubFxProvider) Rate(context.Context, string, string) (money.Decimal, error) {
+	return money.MustParse("0.9"), nil
+}
+
+// stubLineItemRepo serves at most one line item for any timelog and records the writes made to it
+type stubLineItemRepo struct {
+	domain.PaymentLineRepository
+	existing *domain.PaymentLineItem
+	pinned   bool
+	created  *domain.PaymentLineItem
+	patched  map[string]interface{}
+}
+
+func (r *stubLineItemRepo) FindLatestByTimelogID(context.Context, string) (*domain.PaymentLineItem, error) {
+	if r.existing == nil {
+		return nil, scd.ErrRecordNotFound
+	}
+	return r.existing, nil
+}
+
+func (r *stubLineItemRepo) Create(_ context.Context, item *domain.PaymentLineItem) error {
+	item.SCDModel = &scd.SCDModel{ID: "item-1", Version: 1, UID: "item-1-v1", IsLatest: true}
+	r.created = item
+	r.existing = item
+	return nil
+}
+
+func (r *stubLineItemRepo) IsPinned(context.Context, string) (bool, error) {
+	return r.pinned, nil
+}
+
+func (r *stubLineItemRepo) PatchIfVersion(_ context.Context, id string, expectedVersion int, changes map[string]interface{}) (*domain.PaymentLineItem, error) {
+	if expectedVersion != r.existing.GetVersion() {
+		return nil, scd.ErrVersionConflict
+	}
+	r.patched = changes
+	next := *r.existing
+	next.SCDModel = &scd.SCDModel{ID: id, Version: expectedVersion + 1, UID: fmt.Sprintf("%s-v%d", id, expectedVersion+1), IsLatest: true}
+	if timelogUID, ok := changes["timelog_uid"].(string); ok {
+		next.TimelogUID = timelogUID
+	}
+	if amount, ok := changes["amount"].(money.Decimal); ok {
+		next.Amount = amount
+	}
+	if status, ok := changes["status"].(domain.PaymentLineItemStatus); ok {
+		next.Status = status
+	}
+	r.existing = &next
+	return &next, nil
+}
+
+func TestGenerateForTimelog(t *testing.T) {
+	ctx := context.Background()
+	timelog := domain.NewTimelog(time.Hour.Milliseconds(), 0, time.Hour.Milliseconds(), "captured", "job-1-v1")
+	timelog.SCDModel = &scd.SCDModel{ID: "timelog-1", Version: 2, UID: "timelog-1-v2", IsLatest: true}
+	timelog.ApprovalStatus = domain.TimelogApprovalStatusApproved
+	lineItem := func(timelogUID string, amount string) *domain.PaymentLineItem {
+		item := domain.NewPaymentLineItem("job-1-v1", timelogUID, money.MustParse(amount), "USD", "EUR", money.MustParse("0.9"), domain.PaymentLineItemStatusApproved)
+		item.SCDModel = &scd.SCDModel{ID: "item-1", Version: 1, UID: "item-1-v1", IsLatest: true}
+		return item
+	}
+
+	t.Run("should create the line item of a new timelog", func(t *testing.T) {
+		repo := &stubLineItemRepo{}
+		generator := NewLineItemGenerator(repo, stubJobRepo{rate: money.NewFromInt(40)}, stubContractorRepo{}, stubFxProvider{})
+
+		item, err := generator.GenerateForTimelog(ctx, timelog)
+
+		assert.NoError(t, err)
+		assert.Same(t, repo.created, item)
+		assert.Equal(t, money.MustParse("40.00"), item.Amount)
+		assert.Equal(t, "timelog-1-v2", item.TimelogUID)
+		assert.Equal(t, "EUR", item.PayoutCurrency)
+		assert.Equal(t, money.MustParse("0.9"), item.FxRate)
+		assert.Equal(t, domain.PaymentLineItemStatusPending, item.Status)
+	})
+
+	t.Run("should keep a line item that still prices the timelog", func(t *testing.T) {
+		existing := lineItem("timelog-1-v2", "40.00")
+		repo := &stubLineItemRepo{existing: existing}
+		generator := NewLineItemGenerator(repo, stubJobRepo{rate: money.NewFromInt(40)}, stubContractorRepo{}, stubFxProvider{})
+
+		item, err := generator.GenerateForTimelog(ctx, timelog)
+
+		assert.NoError(t, err)
+		assert.Same(t, existing, item)
+		assert.Nil(t, repo.created)
+		assert.Nil(t, repo.patched)
+	})
+
+	t.Run("should re-price the line item of a changed timelog and withdraw its approval", func(t *testing.T) {
+		repo := &stubLineItemRepo{existing: lineItem("timelog-1-v1", "20.00")}
+		generator := NewLineItemGenerator(repo, stubJobRepo{rate: money.NewFromInt(40)}, stubContractorRepo{}, stubFxProvider{})
+
+		item, err := generator.GenerateForTimelog(ctx, timelog)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"job_uid":         "job-1-v1",
+			"timelog_uid":     "timelog-1-v2",
+			"amount":          money.MustParse("40.00"),
+			"currency":        "USD",
+			"payout_currency": "EUR",
+			"fx_rate":         money.MustParse("0.9"),
+			"status":          domain.PaymentLineItemStatusPending,
+		}, repo.patched)
+		assert.Equal(t, money.MustParse("40.00"), item.Amount)
+		assert.Equal(t, domain.PaymentLineItemStatusPending, item.Status)
+		assert.Equal(t, 2, item.GetVersion())
+	})
+
+	t.Run("should keep the approval of a line item whose price didn't change", func(t *testing.T) {
+		repo := &stubLineItemRepo{existing: lineItem("timelog-1-v1", "40.00")}
+		generator := NewLineItemGenerator(repo, stubJobRepo{rate: money.NewFromInt(40)}, stubContractorRepo{}, stubFxProvider{})
+
+		item, err := generator.GenerateForTimelog(ctx, timelog)
+
+		assert.NoError(t, err)
+		assert.NotContains(t, repo.patched, "status")
+		assert.Equal(t, "timelog-1-v2", item.TimelogUID)
+		assert.Equal(t, domain.PaymentLineItemStatusApproved, item.Status)
+	})
+
+	t.Run("should refuse to re-price a line item held by a payout or an invoice", func(t *testing.T) {
+		repo := &stubLineItemRepo{existing: lineItem("timelog-1-v1", "20.00"), pinned: true}
+		generator := NewLineItemGenerator(repo, stubJobRepo{rate: money.NewFromInt(40)}, stubContractorRepo{}, stubFxProvider{})
+
+		_, err := generator.GenerateForTimelog(ctx, timelog)
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeConflict), err)
+		assert.Nil(t, repo.patched)
+	})
+
+	t.Run("should refuse to re-price a paid line item", func(t *testing.T) {
+		existing := lineItem("timelog-1-v1", "20.00")
+		existing.Status = domain.PaymentLineItemStatusPaid
+		repo := &stubLineItemRepo{existing: existing}
+		generator := NewLineItemGenerator(repo, stubJobRepo{rate: money.NewFromInt(40)}, stubContractorRepo{}, stubFxProvider{})
+
+		_, err := generator.GenerateForTimelog(ctx, timelog)
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeInvalidTransition), err)
+		assert.Nil(t, repo.patched)
+	})
+}
+
+func TestApproveForTimelog(t *testing.T) {
+	ctx := context.Background()
+	timelog := domain.NewTimelog(time.Hour.Milliseconds(), 0, time.Hour.Milliseconds(), "captured", "job-1-v1")
+	timelog.SCDModel = &scd.SCDModel{ID: "timelog-1", Version: 2, UID: "timelog-1-v2", IsLatest: true}
+	timelog.ApprovalStatus = domain.TimelogApprovalStatusApproved
+	lineItem := func(timelogUID string, amount string, status domain.PaymentLineItemStatus) *domain.PaymentLineItem {
+		item := domain.NewPaymentLineItem("job-1-v1", timelogUID, money.MustParse(amount), "USD", "EUR", money.MustParse("0.9"), status)
+		item.SCDModel = &scd.SCDModel{ID: "item-1", Version: 1, UID: "item-1-v1", IsLatest: true}
+		return item
+	}
+
+	tests := []struct {
+		name        string
+		existing    *domain.PaymentLineItem
+		wantStatus  domain.PaymentLineItemStatus
+		wantVersion int
+	}{
+		{
+			name:        "should create and approve the line item of a newly approved timelog",
+			wantStatus:  domain.PaymentLineItemStatusApproved,
+			wantVersion: 2,
+		},
+		{
+			name:        "should approve a pending line item",
+			existing:    lineItem("timelog-1-v2", "40.00", domain.PaymentLineItemStatusPending),
+			wantStatus:  domain.PaymentLineItemStatusApproved,
+			wantVersion: 2,
+		},
+		{
+			name:        "should approve the new price of a re-priced line item",
+			existing:    lineItem("timelog-1-v1", "20.00", domain.PaymentLineItemStatusApproved),
+			wantStatus:  domain.PaymentLineItemStatusApproved,
+			wantVersion: 3,
+		},
+		{
+			name:        "should keep an approved line item",
+			existing:    lineItem("timelog-1-v2", "40.00", domain.PaymentLineItemStatusApproved),
+			wantStatus:  domain.PaymentLineItemStatusApproved,
+			wantVersion: 1,
+		},
+		{
+			name:        "should keep a paid line item",
+			existing:    lineItem("timelog-1-v2", "40.00", domain.PaymentLineItemStatusPaid),
+			wantStatus:  domain.PaymentLineItemStatusPaid,
+			wantVersion: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubLineItemRepo{existing: tt.existing}
+			generator := NewLineItemGenerator(repo, stubJobRepo{rate: money.NewFromInt(40)}, stubContractorRepo{}, stubFxProvider{})
+
+			item, err := generator.ApproveForTimelog(ctx, timelog)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, item.Status)
+			assert.Equal(t, tt.wantVersion, item.GetVersion())
+			assert.Equal(t, money.MustParse("40.00"), item.Amount)
+		})
+	}
+
+	t.Run("should refuse to approve the line item of a timelog that isn't approved", func(t *testing.T) {
+		pending := *timelog
+		pending.ApprovalStatus = domain.TimelogApprovalStatusPending
+		repo := &stubLineItemRepo{}
+		generator := NewLineItemGenerator(repo, nil, nil, nil)
+
+		_, err := generator.ApproveForTimelog(ctx, &pending)
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeInvalidTransition), err)
+		assert.Nil(t, repo.created)
+	})
+}
+
+func TestGenerateForTimelogNotApproved(t *testing.T) {
+	lineItem := func(status domain.PaymentLineItemStatus) *domain.PaymentLineItem {
+		item := domain.NewPaymentLineItem("job-1-v1", "timelog-1-v1", money.MustParse("50.00"), "USD", "USD", money.NewFromInt(1), status)
+		item.SCDModel = &scd.SCDModel{ID: "item-1", Version: 1, UID: "item-1-v1", IsLatest: true}
+		return item
+	}
+
+	tests := []struct {
+		name       string
+		approval   domain.TimelogApprovalStatus
+		existing   *domain.PaymentLineItem
+		wantStatus domain.PaymentLineItemStatus
+		wantPatch  bool
+		wantErr    string
+	}{
+		{
+			name:     "should create no line item for a pending timelog",
+			approval: domain.TimelogApprovalStatusPending,
+		},
+		{
+			name:       "should void the pending line item of a rejected timelog",
+			approval:   domain.TimelogApprovalStatusRejected,
+			existing:   lineItem(domain.PaymentLineItemStatusPending),
+			wantStatus: domain.PaymentLineItemStatusVoided,
+			wantPatch:  true,
+		},
+		{
+			name:       "should void the approved line item of a rejected timelog",
+			approval:   domain.TimelogApprovalStatusRejected,
+			existing:   lineItem(domain.PaymentLineItemStatusApproved),
+			wantStatus: domain.PaymentLineItemStatusVoided,
+			wantPatch:  true,
+		},
+		{
+			name:       "should keep a line item that is already voided",
+			approval:   domain.TimelogApprovalStatusRejected,
+			existing:   lineItem(domain.PaymentLineItemStatusVoided),
+			wantStatus: domain.PaymentLineItemStatusVoided,
+		},
+		{
+			name:     "should refuse to void a paid line item",
+			approval: domain.TimelogApprovalStatusRejected,
+			existing: lineItem(domain.PaymentLineItemStatusPaid),
+			wantErr:  "payment line item item-1 of timelog timelog-1 is paid and can no longer be voided",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubLineItemRepo{existing: tt.existing}
+			generator := NewLineItemGenerator(repo, nil, nil, nil)
+			timelog := domain.NewTimelog(time.Hour.Milliseconds(), 0, time.Hour.Milliseconds(), "captured", "job-1-v1")
+			timelog.SCDModel = &scd.SCDModel{ID: "timelog-1", Version: 2, UID: "timelog-1-v2", IsLatest: true}
+			timelog.ApprovalStatus = tt.approval
+
+			item, err := generator.GenerateForTimelog(context.Background(), timelog)
+
+			if tt.wantErr != "" {
+				appErr, ok := apperror.As(err)
+				if assert.True(t, ok, err) {
+					assert.Equal(t, apperror.CodeInvalidTransition, appErr.Code)
+					assert.Equal(t, tt.wantErr, appErr.Message)
+				}
+				assert.Nil(t, repo.patched)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.existing == nil {
+				assert.Nil(t, item)
+				return
+			}
+			assert.Equal(t, tt.wantStatus, item.Status)
+			if tt.wantPatch {
+				assert.Equal(t, map[string]interface{}{"timelog_uid": "timelog-1-v2", "status": domain.PaymentLineItemStatusVoided}, repo.patched)
+				assert.Equal(t, 2, item.GetVersion())
+			} else {
+				assert.Nil(t, repo.patched)
+			}
+		})
+	}
+}
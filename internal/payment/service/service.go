@@ -2,29 +2,179 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
-	"github.com/mercor/payment-service/internal/controller/payment/request"
 	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/payment/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
 type PaymentService struct {
 	repo domain.PaymentLineRepository
+	tx   postgres.Transactor
 }
 
-func NewPaymentService(repo domain.PaymentLineRepository) *PaymentService {
-	return &PaymentService{repo: repo}
+func NewPaymentService(repo domain.PaymentLineRepository, tx postgres.Transactor) *PaymentService {
+	return &PaymentService{repo: repo, tx: tx}
 }
 
-func (s *PaymentService) GetPaymentLineItemsForContractorPeriod(ctx context.Context, contractorID string, startTime, endTime int64) ([]domain.PaymentLineItem, error) {
-	return s.repo.FindByContractorAndPeriod(ctx, contractorID, startTime, endTime)
+func (s *PaymentService) GetPaymentLineItemsForContractorPeriod(ctx context.Context, contractorID string, startTime, endTime int64, page pagination.Request) (*pagination.Page[domain.PaymentLineItem], error) {
+	return s.repo.FindByContractorAndPeriodPage(ctx, contractorID, startTime, endTime, page)
 }
 
-func (s *PaymentService) UpdatePaymentLineItemByID(ctx context.Context, id string, paymentLineItemReq *request.UpdatePaymentRequest) error {
-	paymentLineItem := domain.NewPaymentLineItem(
+// GetEarningsForContractorPeriod aggregates the hours and amounts of the contractor's line items for the period
+func (s *PaymentService) GetEarningsForContractorPeriod(ctx context.Context, contractorID string, startTime, endTime int64, groupBy domain.EarningsGroupBy) (*domain.EarningsSummary, error) {
+	if endTime <= startTime {
+		return nil, apperror.Validation("time_end must be after time_start")
+	}
+
+	earnings, err := s.repo.SumEarningsByContractorAndPeriod(ctx, contractorID, startTime, endTime, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	for i := range earnings {
+		earnings[i].Hours, err = money.NewFromInt(1).MulRatio(earnings[i].Duration, int64(time.Hour/time.Millisecond), money.CentPlaces, money.RoundHalfUp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.EarningsSummary{
+		ContractorID: contractorID,
+		TimeStart:    startTime,
+		TimeEnd:      endTime,
+		GroupBy:      groupBy,
+		Groups:       earnings,
+	}, nil
+}
+
+func (s *PaymentService) GetPaymentLineItemByID(ctx context.Context, id string) (*domain.PaymentLineItem, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *PaymentService) UpdatePaymentLineItemByID(ctx context.Context, id string, paymentLineItemReq *request.UpdatePaymentLineItemSvcReq) (*domain.PaymentLineItem, error) {
+	return s.updatePaymentLineItem(ctx, id, nil, paymentLineItemReq)
+}
+
+// UpdatePaymentLineItemByIDIfVersion updates the line item only if its latest version is still expectedVersion
+func (s *PaymentService) UpdatePaymentLineItemByIDIfVersion(ctx context.Context, id string, expectedVersion int, paymentLineItemReq *request.UpdatePaymentLineItemSvcReq) (*domain.PaymentLineItem, error) {
+	return s.updatePaymentLineItem(ctx, id, &expectedVersion, paymentLineItemReq)
+}
+
+// updatePaymentLineItem checks the status transition against the latest version and writes the new version
+// guarded by that version, so the status can't change in between
+func (s *PaymentService) updatePaymentLineItem(ctx context.Context, id string, expectedVersion *int, paymentLineItemReq *request.UpdatePaymentLineItemSvcReq) (*domain.PaymentLineItem, error) {
+	status, err := domain.ParsePaymentLineItemStatus(paymentLineItemReq.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && latest.GetVersion() != *expectedVersion {
+		return nil, scd.ErrVersionConflict
+	}
+	if err := validateChange(latest, status); err != nil {
+		return nil, err
+	}
+
+	paymentLineItem := newPaymentLineItemFromUpdateRequest(paymentLineItemReq, status)
+	if err := s.repo.UpdateIfVersion(ctx, id, latest.GetVersion(), paymentLineItem); err != nil {
+		return nil, err
+	}
+	return paymentLineItem, nil
+}
+
+// GetPaymentLineItemHistory returns every version of a line item with the fields each version changed
+func (s *PaymentService) GetPaymentLineItemHistory(ctx context.Context, id string) ([]scd.VersionHistory, error) {
+	return s.repo.FindHistoryForID(ctx, id)
+}
+
+// PatchPaymentLineItemByID creates a new version of the line item with only the given columns changed,
+// only if its latest version is still expectedVersion when that is set
+func (s *PaymentService) PatchPaymentLineItemByID(ctx context.Context, id string, expectedVersion *int, updates map[string]interface{}) (*domain.PaymentLineItem, error) {
+	latest, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && latest.GetVersion() != *expectedVersion {
+		return nil, scd.ErrVersionConflict
+	}
+
+	status := latest.Status
+	if value, ok := updates["status"]; ok {
+		status, err = domain.ParsePaymentLineItemStatus(fmt.Sprint(value))
+		if err != nil {
+			return nil, err
+		}
+		updates["status"] = status
+	}
+	if err := validateChange(latest, status); err != nil {
+		return nil, err
+	}
+
+	return s.repo.PatchIfVersion(ctx, id, latest.GetVersion(), updates)
+}
+
+// RevertPaymentLineItemToVersion creates a new version of the line item that copies the given earlier version,
+// the status of that version must be reachable from the latest status. The revert is guarded by the latest version
+// the status was checked against, a write in between fails it with a conflict.
+func (s *PaymentService) RevertPaymentLineItemToVersion(ctx context.Context, id string, version int) (*domain.PaymentLineItem, error) {
+	var reverted *domain.PaymentLineItem
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		versions, err := s.repo.FindVersionsForID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		var latest, target *domain.PaymentLineItem
+		for i := range versions {
+			if versions[i].GetIsLatest() {
+				latest = &versions[i]
+			}
+			if versions[i].GetVersion() == version {
+				target = &versions[i]
+			}
+		}
+		if latest == nil || target == nil {
+			return scd.ErrRecordNotFound
+		}
+		if err := validateChange(latest, target.Status); err != nil {
+			return err
+		}
+
+		reverted, err = s.repo.RevertToVersionIfVersion(ctx, id, version, latest.GetVersion())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reverted, nil
+}
+
+// validateChange rejects changes to line items in a final status and status moves the transition table doesn't allow
+func validateChange(latest *domain.PaymentLineItem, status domain.PaymentLineItemStatus) error {
+	if latest.Status.IsFinal() {
+		return apperror.InvalidTransition(fmt.Sprintf("payment line item %s is %s and can no longer change", latest.GetID(), latest.Status))
+	}
+	return latest.Status.ValidateTransition(status)
+}
+
+func newPaymentLineItemFromUpdateRequest(paymentLineItemReq *request.UpdatePaymentLineItemSvcReq, status domain.PaymentLineItemStatus) *domain.PaymentLineItem {
+	return domain.NewPaymentLineItem(
 		paymentLineItemReq.JobUID,
 		paymentLineItemReq.TimelogUID,
 		paymentLineItemReq.Amount,
-		paymentLineItemReq.Status,
+		paymentLineItemReq.Currency,
+		paymentLineItemReq.PayoutCurrency,
+		paymentLineItemReq.FxRate,
+		status,
 	)
-	return s.repo.Update(ctx, id, paymentLineItem)
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubEarningsRepo serves fixed earnings and records the group_by it was asked for
+type stubEarningsRepo struct {
+	domain.PaymentLineRepository
+	earnings []domain.Earnings
+	groupBy  domain.EarningsGroupBy
+}
+
+func (r *stubEarningsRepo) SumEarningsByContractorAndPeriod(_ context.Context, _ string, _, _ int64, groupBy domain.EarningsGroupBy) ([]domain.Earnings, error) {
+	r.groupBy = groupBy
+	return r.earnings, nil
+}
+
+func TestGetEarningsForContractorPeriod(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should compute the hours of each group from its duration", func(t *testing.T) {
+		tests := []struct {
+			duration time.Duration
+			want     string
+		}{
+			{duration: 0, want: "0.00"},
+			{duration: time.Hour, want: "1.00"},
+			{duration: 90 * time.Minute, want: "1.50"},
+			{duration: 20 * time.Minute, want: "0.33"},
+			{duration: 40 * time.Minute, want: "0.67"},
+			{duration: 18 * time.Second, want: "0.01"},
+			{duration: 17 * time.Second, want: "0.00"},
+			{duration: 100*time.Hour + 30*time.Second, want: "100.01"},
+		}
+
+		earnings := make([]domain.Earnings, len(tests))
+		for i, tt := range tests {
+			earnings[i] = domain.Earnings{Key: "job-1", Currency: "USD", PayoutCurrency: "USD", Duration: tt.duration.Milliseconds()}
+		}
+		repo := &stubEarningsRepo{earnings: earnings}
+		svc := NewPaymentService(repo, postgrestest.Transactor{})
+
+		summary, err := svc.GetEarningsForContractorPeriod(ctx, "contractor-1", 0, 1000, domain.EarningsGroupByWeek)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.EarningsGroupByWeek, summary.GroupBy)
+		assert.Equal(t, domain.EarningsGroupByWeek, repo.groupBy)
+		for i, tt := range tests {
+			assert.Equal(t, money.MustParse(tt.want), summary.Groups[i].Hours, tt.duration.String())
+		}
+	})
+
+	t.Run("should reject a period that ends before it starts", func(t *testing.T) {
+		svc := NewPaymentService(&stubEarningsRepo{}, postgrestest.Transactor{})
+
+		_, err := svc.GetEarningsForContractorPeriod(ctx, "contractor-1", 1000, 1000, domain.EarningsGroupByJob)
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeValidation))
+	})
+}
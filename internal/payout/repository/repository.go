@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/repository/static"
+)
+
+const itemBatchSize = 500
+
+var (
+	repo     *PayoutRepository
+	repoOnce sync.Once
+)
+
+type PayoutRepository struct {
+	static.StaticRepository[domain.Payout]
+	items static.StaticRepository[domain.PayoutItem]
+	db    *postgres.DbCluster
+}
+
+func NewPayoutRepository(db *postgres.DbCluster) domain.PayoutRepository {
+	repoOnce.Do(func() {
+		repo = &PayoutRepository{
+			db:               db,
+			StaticRepository: static.NewStaticRepository[domain.Payout](db),
+			items:            static.NewStaticRepository[domain.PayoutItem](db),
+		}
+	})
+
+	return repo
+}
+
+func (r *PayoutRepository) FindItems(ctx context.Context, payoutID string) ([]domain.PayoutItem, error) {
+	return r.items.GetAllByConditions(ctx, map[string]interface{}{"payout_id": payoutID})
+}
+
+func (r *PayoutRepository) CreateItems(ctx context.Context, items []*domain.PayoutItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.items.CreateInBatch(ctx, items, itemBatchSize)
+}
+
+// ReleaseItems lets the line items of a cancelled payout be paid by another payout
+func (r *PayoutRepository) ReleaseItems(ctx context.Context, payoutID string) error {
+	return r.items.UpdatesByConditions(ctx, map[string]interface{}{"payout_id": payoutID}, map[string]interface{}{"released": true})
+}
+
+// LockContractor serializes the payout writes of a contractor until the surrounding transaction ends
+func (r *PayoutRepository) LockContractor(ctx context.Context, contractorID string) error {
+	err := r.db.GetMasterDB(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "payout:"+contractorID).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock payouts of contractor %s: %w", contractorID, err)
+	}
+	return nil
+}
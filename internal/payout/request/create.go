@@ -0,0 +1,10 @@
+package request
+
+// CreatePayoutSvcReq asks to pay the approved line items of a contractor for a period
+type CreatePayoutSvcReq struct {
+	ContractorID string `json:"contractor_id"`
+	TimeStart    int64  `json:"time_start"`
+	TimeEnd      int64  `json:"time_end"`
+	// Currency defaults to the contractor's payout currency when empty
+	Currency string `json:"currency"`
+}
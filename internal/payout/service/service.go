@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/payout/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/mercor/payment-service/pkg/repository/static"
+)
+
+type PayoutService struct {
+	repo           domain.PayoutRepository
+	paymentRepo    domain.PaymentLineRepository
+	contractorRepo domain.ContractorRepository
+	tx             postgres.Transactor
+}
+
+func NewPayoutService(
+	repo domain.PayoutRepository,
+	paymentRepo domain.PaymentLineRepository,
+	contractorRepo domain.ContractorRepository,
+	tx postgres.Transactor,
+) *PayoutService {
+	return &PayoutService{repo: repo, paymentRepo: paymentRepo, contractorRepo: contractorRepo, tx: tx}
+}
+
+// CreatePayout freezes the latest approved line items of the contractor for the period that no other payout holds
+func (s *PayoutService) CreatePayout(ctx context.Context, req *request.CreatePayoutSvcReq) (*domain.Payout, error) {
+	if req.TimeEnd <= req.TimeStart {
+		return nil, apperror.Validation("time_end must be after time_start")
+	}
+
+	contractor, err := s.contractorRepo.GetByConditions(ctx, map[string]interface{}{"id": req.ContractorID})
+	if errors.Is(err, static.ErrRecordNotFound) {
+		return nil, apperror.NotFound(fmt.Sprintf("contractor %s does not exist", req.ContractorID))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = contractor.PayoutCurrency
+	}
+
+	var payout *domain.Payout
+	err = s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		// Concurrent payouts of the contractor would select the same line items
+		if err := s.repo.LockContractor(ctx, req.ContractorID); err != nil {
+			return err
+		}
+
+		paymentLineItems, err := s.paymentRepo.FindPayableByContractorAndPeriod(ctx, req.ContractorID, req.TimeStart, req.TimeEnd, currency)
+		if err != nil {
+			return err
+		}
+		if len(paymentLineItems) == 0 {
+			return apperror.Validation(fmt.Sprintf("contractor %s has no approved %s line items to pay for the period", req.ContractorID, currency))
+		}
+
+		items := make([]domain.PayoutItem, len(paymentLineItems))
+		for i := range paymentLineItems {
+			if items[i], err = domain.NewPayoutItem(&paymentLineItems[i]); err != nil {
+				return err
+			}
+		}
+
+		payout, err = domain.NewPayout(req.ContractorID, req.TimeStart, req.TimeEnd, currency, items)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, payout); err != nil {
+			return err
+		}
+
+		itemPtrs := make([]*domain.PayoutItem, len(payout.Items))
+		for i := range payout.Items {
+			payout.Items[i].PayoutID = payout.GetID()
+			itemPtrs[i] = &payout.Items[i]
+		}
+		return s.repo.CreateItems(ctx, itemPtrs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payout, nil
+}
+
+func (s *PayoutService) GetPayoutByID(ctx context.Context, id string) (*domain.Payout, error) {
+	payout, err := s.repo.GetByConditions(ctx, map[string]interface{}{"id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	payout.Items, err = s.repo.FindItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return payout, nil
+}
+
+func (s *PayoutService) GetPayoutsForContractor(ctx context.Context, contractorID string, page pagination.Request) (*pagination.Page[domain.Payout], error) {
+	return s.repo.GetAllByConditionsPage(ctx, map[string]interface{}{"contractor_id": contractorID}, page)
+}
+
+// SettlePayout marks the payout settled and every frozen line item version paid. A line item that got a new
+// version after it was frozen fails the settlement with a version conflict, the payout has to be cancelled and
+// created again so that it pays the current amounts.
+func (s *PayoutService) SettlePayout(ctx context.Context, id string) (*domain.Payout, error) {
+	return s.transitionPayout(ctx, id, domain.PayoutStatusSettled, func(ctx context.Context, payout *domain.Payout) error {
+		for _, item := range payout.Items {
+			frozen, err := s.paymentRepo.FindByUID(ctx, item.PaymentLineItemUID)
+			if err != nil {
+				return err
+			}
+
+			_, err = s.paymentRepo.PatchIfVersion(ctx, item.PaymentLineItemID, frozen.GetVersion(), map[string]interface{}{
+				"status": domain.PaymentLineItemStatusPaid,
+			})
+			if errors.Is(err, scd.ErrVersionConflict) {
+				return apperror.Conflict(fmt.Sprintf("payment line item %s changed after it was added to the payout", item.PaymentLineItemID))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		payout.SettledAt = &now
+		return nil
+	})
+}
+
+// CancelPayout cancels a pending payout and releases its line items for another payout
+func (s *PayoutService) CancelPayout(ctx context.Context, id string) (*domain.Payout, error) {
+	return s.transitionPayout(ctx, id, domain.PayoutStatusCancelled, func(ctx context.Context, payout *domain.Payout) error {
+		return s.repo.ReleaseItems(ctx, id)
+	})
+}
+
+// transitionPayout moves a payout to status after apply ran, all in one transaction with the payout row locked
+func (s *PayoutService) transitionPayout(ctx context.Context, id string, status domain.PayoutStatus, apply func(ctx context.Context, payout *domain.Payout) error) (*domain.Payout, error) {
+	var payout *domain.Payout
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		payout, err = s.GetPayoutByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Settling and cancelling the same payout concurrently must not both succeed
+		if err := s.repo.LockContractor(ctx, payout.ContractorID); err != nil {
+			return err
+		}
+		payout, err = s.GetPayoutByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := payout.Status.ValidateTransition(status); err != nil {
+			return err
+		}
+		if err := apply(ctx, payout); err != nil {
+			return err
+		}
+
+		payout.Status = status
+		return s.repo.UpdatesByConditions(ctx, map[string]interface{}{"id": id}, map[string]interface{}{
+			"status":     payout.Status,
+			"settled_at": payout.SettledAt,
+			"updated_at": time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payout, nil
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/payout/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/mercor/payment-service/pkg/repository/static"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubContractorRepo struct {
+	domain.ContractorRepository
+}
+
+func (stubContractorRepo) GetByConditions(context.Context, map[string]interface{}) (*domain.Contractor, error) {
+	return &domain.Contractor{Model: &static.Model{ID: "contractor-1"}, PayoutCurrency: "EUR"}, nil
+}
+
+// stubPayoutRepo keeps payouts and their items in memory
+type stubPayoutRepo struct {
+	domain.PayoutRepository
+	payouts map[string]domain.Payout
+	items   []domain.PayoutItem
+}
+
+func (r *stubPayoutRepo) LockContractor(context.Context, string) error {
+	return nil
+}
+
+func (r *stubPayoutRepo) Create(_ context.Context, payout *domain.Payout) error {
+	payout.SetID(fmt.Sprintf("payout-%d", len(r.payouts)+1))
+	r.payouts[payout.GetID()] = *payout
+	return nil
+}
+
+func (r *stubPayoutRepo) CreateItems(_ context.Context, items []*domain.PayoutItem) error {
+	for _, item := range items {
+		r.items = append(r.items, *item)
+	}
+	return nil
+}
+
+func (r *stubPayoutRepo) GetByConditions(_ context.Context, conditions map[string]interface{}) (*domain.Payout, error) {
+	payout, ok := r.payouts[conditions["id"].(string)]
+	if !ok {
+		return nil, static.ErrRecordNotFound
+	}
+	return &payout, nil
+}
+
+func (r *stubPayoutRepo) FindItems(_ context.Context, payoutID string) ([]domain.PayoutItem, error) {
+	var items []domain.PayoutItem
+	for _, item := range r.items {
+		if item.PayoutID == payoutID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *stubPayoutRepo) ReleaseItems(_ context.Context, payoutID string) error {
+	for i := range r.items {
+		if r.items[i].PayoutID == payoutID {
+			r.items[i].Released = true
+		}
+	}
+	return nil
+}
+
+func (r *stubPayoutRepo) UpdatesByConditions(_ context.Context, conditions map[string]interface{}, updates map[string]interface{}) error {
+	id := conditions["id"].(string)
+	payout := r.payouts[id]
+	payout.Status = updates["status"].(domain.PayoutStatus)
+	r.payouts[id] = payout
+	return nil
+}
+
+// stubPaymentRepo keeps every version of the line items and selects payable ones the way the repository does
+type stubPaymentRepo struct {
+	domain.PaymentLineRepository
+	payouts  *stubPayoutRepo
+	versions map[string]*domain.PaymentLineItem
+	latest   map[string]*domain.PaymentLineItem
+	order    []string
+}
+
+func (r *stubPaymentRepo) put(id string, version int, item *domain.PaymentLineItem) {
+	item.SCDModel = &scd.SCDModel{ID: id, Version: version, UID: fmt.Sprintf("%s-v%d", id, version), IsLatest: true}
+	if previous, ok := r.latest[id]; ok {
+		previous.IsLatest = false
+	} else {
+		r.order = append(r.order, id)
+	}
+	r.versions[item.UID] = item
+	r.latest[id] = item
+}
+
+func (r *stubPaymentRepo) held(id string) bool {
+	for _, item := range r.payouts.items {
+		if item.PaymentLineItemID == id && !item.Released {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *stubPaymentRepo) FindPayableByContractorAndPeriod(_ context.Context, _ string, _, _ int64, payoutCurrency string) ([]domain.PaymentLineItem, error) {
+	var items []domain.PaymentLineItem
+	for _, id := range r.order {
+		item := r.latest[id]
+		if item.Status == domain.PaymentLineItemStatusApproved && item.PayoutCurrency == payoutCurrency && !r.held(id) {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+func (r *stubPaymentRepo) FindByUID(_ context.Context, uid string) (*domain.PaymentLineItem, error) {
+	item, ok := r.versions[uid]
+	if !ok {
+		return nil, scd.ErrRecordNotFound
+	}
+	return item, nil
+}
+
+func (r *stubPaymentRepo) PatchIfVersion(_ context.Context, id string, expectedVersion int, changes map[string]interface{}) (*domain.PaymentLineItem, error) {
+	latest := r.latest[id]
+	if latest.Version != expectedVersion {
+		return nil, scd.ErrVersionConflict
+	}
+	next := *latest
+	next.Status = changes["status"].(domain.PaymentLineItemStatus)
+	r.put(id, expectedVersion+1, &next)
+	return &next, nil
+}
+
+func newTestPayoutService() (*PayoutService, *stubPaymentRepo) {
+	payoutRepo := &stubPayoutRepo{payouts: map[string]domain.Payout{}}
+	paymentRepo := &stubPaymentRepo{
+		payouts:  payoutRepo,
+		versions: map[string]*domain.PaymentLineItem{},
+		latest:   map[string]*domain.PaymentLineItem{},
+	}
+	return NewPayoutService(payoutRepo, paymentRepo, stubContractorRepo{}, postgrestest.Transactor{}), paymentRepo
+}
+
+func lineItem(status domain.PaymentLineItemStatus, payoutCurrency string) *domain.PaymentLineItem {
+	return domain.NewPaymentLineItem("job-1-v1", "timelog-1-v1", money.MustParse("100.00"), "USD", payoutCurrency, money.MustParse("0.9"), status)
+}
+
+func payoutItemIDs(payout *domain.Payout) []string {
+	ids := make([]string, len(payout.Items))
+	for i, item := range payout.Items {
+		ids[i] = item.PaymentLineItemID
+	}
+	return ids
+}
+
+func TestCreatePayout(t *testing.T) {
+	ctx := context.Background()
+	req := &request.CreatePayoutSvcReq{ContractorID: "contractor-1", TimeStart: 0, TimeEnd: 1000}
+
+	t.Run("should pay only the approved line items in the payout currency", func(t *testing.T) {
+		svc, paymentRepo := newTestPayoutService()
+		paymentRepo.put("approved-eur", 1, lineItem(domain.PaymentLineItemStatusApproved, "EUR"))
+		paymentRepo.put("pending-eur", 1, lineItem(domain.PaymentLineItemStatusPending, "EUR"))
+		paymentRepo.put("voided-eur", 1, lineItem(domain.PaymentLineItemStatusVoided, "EUR"))
+		paymentRepo.put("paid-eur", 1, lineItem(domain.PaymentLineItemStatusPaid, "EUR"))
+		paymentRepo.put("approved-usd", 1, lineItem(domain.PaymentLineItemStatusApproved, "USD"))
+
+		payout, err := svc.CreatePayout(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "EUR", payout.Currency)
+		assert.Equal(t, []string{"approved-eur"}, payoutItemIDs(payout))
+		assert.Equal(t, "approved-eur-v1", payout.Items[0].PaymentLineItemUID)
+		assert.Equal(t, money.MustParse("90.00"), payout.TotalAmount)
+	})
+
+	t.Run("should refuse a payout without payable line items", func(t *testing.T) {
+		svc, paymentRepo := newTestPayoutService()
+		paymentRepo.put("pending-eur", 1, lineItem(domain.PaymentLineItemStatusPending, "EUR"))
+
+		_, err := svc.CreatePayout(ctx, req)
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeValidation), err)
+	})
+
+	t.Run("should not select a line item held by a pending payout", func(t *testing.T) {
+		svc, paymentRepo := newTestPayoutService()
+		paymentRepo.put("item-1", 1, lineItem(domain.PaymentLineItemStatusApproved, "EUR"))
+
+		_, err := svc.CreatePayout(ctx, req)
+		assert.NoError(t, err)
+
+		_, err = svc.CreatePayout(ctx, req)
+		assert.True(t, apperror.HasCode(err, apperror.CodeValidation), err)
+	})
+
+	t.Run("should select the line items of a cancelled payout again", func(t *testing.T) {
+		svc, paymentRepo := newTestPayoutService()
+		paymentRepo.put("item-1", 1, lineItem(domain.PaymentLineItemStatusApproved, "EUR"))
+
+		first, err := svc.CreatePayout(ctx, req)
+		assert.NoError(t, err)
+		cancelled, err := svc.CancelPayout(ctx, first.GetID())
+		assert.NoError(t, err)
+		assert.Equal(t, domain.PayoutStatusCancelled, cancelled.Status)
+
+		second, err := svc.CreatePayout(ctx, req)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, first.GetID(), second.GetID())
+		assert.Equal(t, []string{"item-1"}, payoutItemIDs(second))
+	})
+}
+
+func TestSettlePayout(t *testing.T) {
+	ctx := context.Background()
+	req := &request.CreatePayoutSvcReq{ContractorID: "contractor-1", TimeStart: 0, TimeEnd: 1000}
+
+	t.Run("should mark the frozen line items paid", func(t *testing.T) {
+		svc, paymentRepo := newTestPayoutService()
+		paymentRepo.put("item-1", 1, lineItem(domain.PaymentLineItemStatusApproved, "EUR"))
+		payout, err := svc.CreatePayout(ctx, req)
+		assert.NoError(t, err)
+
+		settled, err := svc.SettlePayout(ctx, payout.GetID())
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.PayoutStatusSettled, settled.Status)
+		assert.NotNil(t, settled.SettledAt)
+		assert.Equal(t, domain.PaymentLineItemStatusPaid, paymentRepo.latest["item-1"].Status)
+		assert.Equal(t, 2, paymentRepo.latest["item-1"].Version)
+	})
+
+	t.Run("should conflict when a line item changed after it was frozen", func(t *testing.T) {
+		svc, paymentRepo := newTestPayoutService()
+		paymentRepo.put("item-1", 1, lineItem(domain.PaymentLineItemStatusApproved, "EUR"))
+		payout, err := svc.CreatePayout(ctx, req)
+		assert.NoError(t, err)
+
+		changed := *paymentRepo.latest["item-1"]
+		changed.Amount = money.MustParse("150.00")
+		paymentRepo.put("item-1", 2, &changed)
+
+		_, err = svc.SettlePayout(ctx, payout.GetID())
+
+		appErr, ok := apperror.As(err)
+		if assert.True(t, ok, err) {
+			assert.Equal(t, apperror.CodeConflict, appErr.Code)
+			assert.Equal(t, "payment line item item-1 changed after it was added to the payout", appErr.Message)
+		}
+		assert.Equal(t, domain.PaymentLineItemStatusApproved, paymentRepo.latest["item-1"].Status)
+	})
+
+	t.Run("should refuse to settle a cancelled payout", func(t *testing.T) {
+		svc, paymentRepo := newTestPayoutService()
+		paymentRepo.put("item-1", 1, lineItem(domain.PaymentLineItemStatusApproved, "EUR"))
+		payout, err := svc.CreatePayout(ctx, req)
+		assert.NoError(t, err)
+		_, err = svc.CancelPayout(ctx, payout.GetID())
+		assert.NoError(t, err)
+
+		_, err = svc.SettlePayout(ctx, payout.GetID())
+
+		assert.True(t, apperror.HasCode(err, apperror.CodeInvalidTransition), err)
+	})
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/mercor/payment-service/internal/domain"
 	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"github.com/mercor/payment-service/pkg/repository/scd"
 	"gorm.io/gorm"
 )
@@ -33,14 +35,42 @@ func NewTimelogRepository(db *postgres.DbCluster) domain.TimeLogRepositoryInterf
 
 func (r *TimelogRepository) FindByContractorAndPeriod(ctx context.Context, contractorID string, startDate, endDate int64) ([]domain.Timelog, error) {
 
-	timelogs, err := r.CustomQuery(ctx, func(db *gorm.DB) *gorm.DB {
+	timelogs, err := r.CustomQuery(ctx, byContractorAndPeriod(contractorID, startDate, endDate))
+	if err != nil {
+		return nil, err
+	}
+
+	return timelogs, nil
+}
+
+func (r *TimelogRepository) FindByContractorAndPeriodPage(ctx context.Context, contractorID string, startDate, endDate int64, page pagination.Request) (*pagination.Page[domain.Timelog], error) {
+	return r.CustomQueryPage(ctx, byContractorAndPeriod(contractorID, startDate, endDate), page)
+}
+
+// FindOverlapping returns the latest timelogs of the contractor that share any time with [timeStart, timeEnd),
+// rejected timelogs don't hold their time
+func (r *TimelogRepository) FindOverlapping(ctx context.Context, contractorID string, timeStart, timeEnd int64) ([]domain.Timelog, error) {
+	return r.CustomQuery(ctx, func(db *gorm.DB) *gorm.DB {
 		return db.
 			Joins("JOIN job ON job.uid = timelog.job_uid").
-			Where("job.contractor_id = ? AND timelog.time_start > ? AND timelog.time_end < ?", contractorID, startDate, endDate)
+			Where("job.contractor_id = ? AND timelog.time_start < ? AND timelog.time_end > ?", contractorID, timeEnd, timeStart).
+			Where("timelog.approval_status <> ?", domain.TimelogApprovalStatusRejected)
 	})
+}
+
+// LockContractor serializes the timelog writes of a contractor until the surrounding transaction ends
+func (r *TimelogRepository) LockContractor(ctx context.Context, contractorID string) error {
+	err := r.db.GetMasterDB(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "timelog:"+contractorID).Error
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to lock timelogs of contractor %s: %w", contractorID, err)
 	}
+	return nil
+}
 
-	return timelogs, nil
+func byContractorAndPeriod(contractorID string, startDate, endDate int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("JOIN job ON job.uid = timelog.job_uid").
+			Where("job.contractor_id = ? AND timelog.time_start > ? AND timelog.time_end < ?", contractorID, startDate, endDate)
+	}
 }
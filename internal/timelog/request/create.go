@@ -1,12 +1,10 @@
 package request
 
-// Add timelog service request structs here as needed.
-
-// CreateTimelogRequest represents a request to create a new timelog entry
-type CreateTimelogRequest struct {
-	ContractorID string  `json:"contractor_id" binding:"required"`
-	JobID        string  `json:"job_id" binding:"required"`
-	Date         string  `json:"date" binding:"required"`
-	Hours        float64 `json:"hours" binding:"required"`
-	Description  string  `json:"description"`
+// CreateTimelogSvcReq represents a request to log time against the latest version of a job
+type CreateTimelogSvcReq struct {
+	JobID     string `json:"job_id"`
+	TimeStart int64  `json:"time_start"`
+	TimeEnd   int64  `json:"time_end"`
+	Duration  int64  `json:"duration"`
+	Type      string `json:"type"`
 }
@@ -0,0 +1,8 @@
+package request
+
+// ReviewTimelogSvcReq approves or rejects the latest version of a timelog on behalf of the reviewer
+type ReviewTimelogSvcReq struct {
+	TimelogID  string `json:"timelog_id"`
+	ReviewerID string `json:"reviewer_id"`
+	Reason     string `json:"reason"`
+}
@@ -2,18 +2,257 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 
 	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/timelog/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"github.com/mercor/payment-service/pkg/repository/scd"
 )
 
 type TimelogService struct {
-	repo domain.TimeLogRepositoryInterface
+	repo      domain.TimeLogRepositoryInterface
+	jobRepo   domain.JobRepositoryInterface
+	lineItems domain.PaymentLineItemGeneratorInterface
+	tx        postgres.Transactor
 }
 
-func NewTimelogService(repo domain.TimeLogRepositoryInterface) *TimelogService {
-	return &TimelogService{repo: repo}
+func NewTimelogService(
+	repo domain.TimeLogRepositoryInterface,
+	jobRepo domain.JobRepositoryInterface,
+	lineItems domain.PaymentLineItemGeneratorInterface,
+	tx postgres.Transactor,
+) *TimelogService {
+	return &TimelogService{repo: repo, jobRepo: jobRepo, lineItems: lineItems, tx: tx}
 }
 
-func (s *TimelogService) GetTimelogsForContractorPeriod(ctx context.Context, contractorID string, startDate, endDate int64) ([]domain.Timelog, error) {
-	return s.repo.FindByContractorAndPeriod(ctx, contractorID, startDate, endDate)
+// CreateTimelog logs time against the latest version of an active job
+func (s *TimelogService) CreateTimelog(ctx context.Context, req *request.CreateTimelogSvcReq) (*domain.Timelog, error) {
+	timelogs, err := s.CreateTimelogs(ctx, []*request.CreateTimelogSvcReq{req})
+	if err != nil {
+		return nil, err
+	}
+	return &timelogs[0], nil
+}
+
+// CreateTimelogs creates all timelogs and their payment line items in one transaction, nothing is written
+// when any of them is invalid or overlaps another timelog of the same contractor
+func (s *TimelogService) CreateTimelogs(ctx context.Context, reqs []*request.CreateTimelogSvcReq) ([]domain.Timelog, error) {
+	timelogs := make([]*domain.Timelog, len(reqs))
+	contractorIDs := make([]string, len(reqs))
+
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		for i, req := range reqs {
+			job, err := s.validateCreateTimelogRequest(ctx, req)
+			if err != nil {
+				return atIndex(err, i, len(reqs))
+			}
+			timelogs[i] = domain.NewTimelog(req.Duration, req.TimeStart, req.TimeEnd, req.Type, job.UID)
+			contractorIDs[i] = job.ContractorID
+
+			for j := 0; j < i; j++ {
+				if contractorIDs[j] == contractorIDs[i] && overlaps(timelogs[j], timelogs[i]) {
+					return atIndex(apperror.Validation(fmt.Sprintf("timelog overlaps timelog %d of the batch", j)), i, len(reqs))
+				}
+			}
+		}
+
+		// Lock the contractors in a fixed order so concurrent batches can't deadlock
+		for _, contractorID := range uniqueSorted(contractorIDs) {
+			if err := s.repo.LockContractor(ctx, contractorID); err != nil {
+				return err
+			}
+		}
+
+		for i, timelog := range timelogs {
+			overlapping, err := s.repo.FindOverlapping(ctx, contractorIDs[i], timelog.TimeStart, timelog.TimeEnd)
+			if err != nil {
+				return err
+			}
+			if len(overlapping) > 0 {
+				return atIndex(apperror.Conflict(fmt.Sprintf("timelog overlaps timelog %s", overlapping[0].GetID())), i, len(reqs))
+			}
+		}
+
+		recordErrs, err := s.repo.CreateMany(ctx, timelogs)
+		if err != nil {
+			return err
+		}
+		if err := errors.Join(recordErrs...); err != nil {
+			return err
+		}
+
+		for _, timelog := range timelogs {
+			if _, err := s.lineItems.GenerateForTimelog(ctx, timelog); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]domain.Timelog, len(timelogs))
+	for i, timelog := range timelogs {
+		created[i] = *timelog
+	}
+	return created, nil
+}
+
+func (s *TimelogService) validateCreateTimelogRequest(ctx context.Context, req *request.CreateTimelogSvcReq) (*domain.Job, error) {
+	if req.TimeEnd <= req.TimeStart {
+		return nil, apperror.Validation("time_end must be after time_start")
+	}
+	if req.Duration != req.TimeEnd-req.TimeStart {
+		return nil, apperror.Validation(fmt.Sprintf("duration %d does not match time_end - time_start %d", req.Duration, req.TimeEnd-req.TimeStart))
+	}
+
+	job, err := s.jobRepo.FindByID(ctx, req.JobID)
+	if errors.Is(err, scd.ErrRecordNotFound) {
+		return nil, apperror.Validation(fmt.Sprintf("job %s does not exist", req.JobID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !job.Status.IsActive() {
+		return nil, apperror.Validation(fmt.Sprintf("job %s is %s, time can only be logged against active jobs", req.JobID, job.Status))
+	}
+	return job, nil
+}
+
+func (s *TimelogService) GetTimelogsForContractorPeriod(ctx context.Context, contractorID string, startDate, endDate int64, page pagination.Request) (*pagination.Page[domain.Timelog], error) {
+	return s.repo.FindByContractorAndPeriodPage(ctx, contractorID, startDate, endDate, page)
+}
+
+// GetTimelogHistory returns every version of a timelog with the fields each version changed
+func (s *TimelogService) GetTimelogHistory(ctx context.Context, id string) ([]scd.VersionHistory, error) {
+	return s.repo.FindHistoryForID(ctx, id)
+}
+
+// RevertTimelogToVersion creates a new version of the timelog that copies the given earlier version
+// and re-versions its payment line item to match, the line item of an approved version is approved. Rejected hours are final and can't be reverted,
+// the revert is guarded by the latest version that was checked.
+func (s *TimelogService) RevertTimelogToVersion(ctx context.Context, id string, version int) (*domain.Timelog, error) {
+	var timelog *domain.Timelog
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		latest, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if latest.ApprovalStatus == domain.TimelogApprovalStatusRejected {
+			return apperror.InvalidTransition(fmt.Sprintf("timelog %s is rejected and can no longer change", id))
+		}
+
+		timelog, err = s.repo.RevertToVersionIfVersion(ctx, id, version, latest.GetVersion())
+		if err != nil {
+			return err
+		}
+
+		return s.syncLineItem(ctx, timelog)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return timelog, nil
+}
+
+// ApproveTimelog approves the hours of the latest timelog version, which generates its payment line item and approves
+// it for the next payout
+func (s *TimelogService) ApproveTimelog(ctx context.Context, req *request.ReviewTimelogSvcReq) (*domain.Timelog, error) {
+	return s.reviewTimelog(ctx, req, domain.TimelogApprovalStatusApproved)
+}
+
+// RejectTimelog rejects the hours of the latest timelog version and voids its payment line item if it has one
+func (s *TimelogService) RejectTimelog(ctx context.Context, req *request.ReviewTimelogSvcReq) (*domain.Timelog, error) {
+	return s.reviewTimelog(ctx, req, domain.TimelogApprovalStatusRejected)
+}
+
+// reviewTimelog writes a new timelog version with the approval status, reviewer and reason guarded by the version
+// the transition was checked against, and brings the payment line item in line in the same transaction: an approval
+// approves it, a rejection voids it
+func (s *TimelogService) reviewTimelog(ctx context.Context, req *request.ReviewTimelogSvcReq, status domain.TimelogApprovalStatus) (*domain.Timelog, error) {
+	if req.ReviewerID == "" {
+		return nil, apperror.Unauthorized("reviewing a timelog requires an authenticated user")
+	}
+
+	var timelog *domain.Timelog
+	err := s.tx.RunInTx(ctx, func(ctx context.Context) error {
+		latest, err := s.repo.FindByID(ctx, req.TimelogID)
+		if err != nil {
+			return err
+		}
+		if err := latest.ApprovalStatus.ValidateTransition(status); err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{
+			"approval_status": status,
+			"approver_id":     &req.ReviewerID,
+			"approval_reason": nullableString(req.Reason),
+		}
+		changeCtx := scd.WithChangeInfo(ctx, req.ReviewerID, req.Reason)
+		timelog, err = s.repo.PatchIfVersion(changeCtx, req.TimelogID, latest.GetVersion(), changes)
+		if err != nil {
+			return err
+		}
+
+		return s.syncLineItem(ctx, timelog)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return timelog, nil
+}
+
+// syncLineItem brings the payment line item in line with the timelog version, the line item of an approved version is
+// approved so it can be paid out
+func (s *TimelogService) syncLineItem(ctx context.Context, timelog *domain.Timelog) error {
+	var err error
+	if timelog.IsApproved() {
+		_, err = s.lineItems.ApproveForTimelog(ctx, timelog)
+	} else {
+		_, err = s.lineItems.GenerateForTimelog(ctx, timelog)
+	}
+	return err
+}
+
+// overlaps reports whether the half-open intervals of two timelogs share any time
+func overlaps(a, b *domain.Timelog) bool {
+	return a.TimeStart < b.TimeEnd && b.TimeStart < a.TimeEnd
+}
+
+// atIndex prefixes the error of a batch entry with its index, single entry requests keep the error as is
+func atIndex(err error, index, size int) error {
+	if size == 1 {
+		return err
+	}
+	if appErr, ok := apperror.As(err); ok {
+		return apperror.New(appErr.Code, fmt.Sprintf("timelogs[%d]: %s", index, appErr.Message))
+	}
+	return fmt.Errorf("timelogs[%d]: %w", index, err)
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+	return unique
 }
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mercor/payment-service/internal/domain"
+	"github.com/mercor/payment-service/internal/timelog/request"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/mercor/payment-service/pkg/repository/scd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlaps(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int64
+		want       bool
+	}{
+		{name: "should not overlap a timelog ending where the other starts", start: 0, end: 100, want: false},
+		{name: "should not overlap a timelog starting where the other ends", start: 200, end: 300, want: false},
+		{name: "should not overlap a disjoint timelog", start: 400, end: 500, want: false},
+		{name: "should overlap a timelog nested in the other", start: 120, end: 180, want: true},
+		{name: "should overlap a timelog the other is nested in", start: 50, end: 250, want: true},
+		{name: "should overlap a timelog that covers its start", start: 50, end: 150, want: true},
+		{name: "should overlap a timelog that covers its end", start: 150, end: 250, want: true},
+		{name: "should overlap the same interval", start: 100, end: 200, want: true},
+	}
+
+	timelog := domain.NewTimelog(100, 100, 200, "captured", "job-v1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := domain.NewTimelog(tt.end-tt.start, tt.start, tt.end, "captured", "job-v1")
+
+			assert.Equal(t, tt.want, overlaps(timelog, other))
+			assert.Equal(t, tt.want, overlaps(other, timelog))
+		})
+	}
+}
+
+func TestAtIndex(t *testing.T) {
+	t.Run("should keep the error of a single entry request", func(t *testing.T) {
+		err := apperror.Validation("bad")
+
+		assert.Equal(t, err, atIndex(err, 0, 1))
+	})
+
+	t.Run("should prefix a domain error with the index and keep its code", func(t *testing.T) {
+		err := atIndex(apperror.Conflict("overlaps"), 2, 3)
+
+		appErr, ok := apperror.As(err)
+		assert.True(t, ok)
+		assert.Equal(t, apperror.CodeConflict, appErr.Code)
+		assert.Equal(t, "timelogs[2]: overlaps", appErr.Message)
+	})
+
+	t.Run("should wrap other errors with the index", func(t *testing.T) {
+		cause := errors.New("connection reset")
+		err := atIndex(cause, 1, 2)
+
+		assert.ErrorIs(t, err, cause)
+		assert.Equal(t, "timelogs[1]: connection reset", err.Error())
+	})
+}
+
+func TestUniqueSorted(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, uniqueSorted([]string{"c", "a", "b", "a", "c"}))
+	assert.Equal(t, []string{}, uniqueSorted(nil))
+}
+
+// stubJobRepo serves active jobs whose contractor is named by the job id
+type stubJobRepo struct {
+	domain.JobRepositoryInterface
+}
+
+func (stubJobRepo) FindByID(_ context.Context, id string) (*domain.Job, error) {
+	job := domain.NewJob("active", money.NewFromInt(10), "USD", "Engineer", "company-1", "contractor-of-"+id)
+	job.SCDModel = &scd.SCDModel{ID: id, Version: 1, UID: id + "-v1", IsLatest: true}
+	return job, nil
+}
+
+type stubTimelogRepo struct {
+	domain.TimeLogRepositoryInterface
+	created  []*domain.Timelog
+	latest   *domain.Timelog
+	reverted map[int]*domain.Timelog
+}
+
+func (r *stubTimelogRepo) FindByID(context.Context, string) (*domain.Timelog, error) {
+	return r.latest, nil
+}
+
+func (r *stubTimelogRepo) RevertToVersionIfVersion(_ context.Context, id string, version int, expectedVersion int) (*domain.Timelog, error) {
+	if expectedVersion != r.latest.GetVersion() {
+		return nil, scd.ErrVersionConflict
+	}
+	reverted := *r.reverted[version]
+	reverted.SCDModel = &scd.SCDModel{ID: id, Version: expectedVersion + 1, IsLatest: true}
+	r.latest = &reverted
+	return &reverted, nil
+}
+
+func (r *stubTimelogRepo) PatchIfVersion(_ context.Context, id string, expectedVersion int, changes map[string]interface{}) (*domain.Timelog, error) {
+	if expectedVersion != r.latest.GetVersion() {
+		return nil, scd.ErrVersionConflict
+	}
+	next := *r.latest
+	next.SCDModel = &scd.SCDModel{ID: id, Version: expectedVersion + 1, IsLatest: true}
+	next.ApprovalStatus = changes["approval_status"].(domain.TimelogApprovalStatus)
+	r.latest = &next
+	return &next, nil
+}
+
+func (r *stubTimelogRepo) LockContractor(context.Context, string) error {
+	return nil
+}
+
+func (r *stubTimelogRepo) FindOverlapping(context.Context, string, int64, int64) ([]domain.Timelog, error) {
+	return nil, nil
+}
+
+func (r *stubTimelogRepo) CreateMany(_ context.Context, timelogs []*domain.Timelog) ([]error, error) {
+	r.created = append(r.created, timelogs...)
+	return make([]error, len(timelogs)), nil
+}
+
+// stubGenerator records the line item status each call left the timelog with
+type stubGenerator struct {
+	status domain.PaymentLineItemStatus
+}
+
+func (g *stubGenerator) GenerateForTimelog(_ context.Context, timelog *domain.Timelog) (*domain.PaymentLineItem, error) {
+	if timelog.ApprovalStatus == domain.TimelogApprovalStatusRejected {
+		g.status = domain.PaymentLineItemStatusVoided
+	} else if timelog.IsApproved() {
+		g.status = domain.PaymentLineItemStatusPending
+	}
+	return nil, nil
+}
+
+func (g *stubGenerator) ApproveForTimelog(context.Context, *domain.Timelog) (*domain.PaymentLineItem, error) {
+	g.status = domain.PaymentLineItemStatusApproved
+	return nil, nil
+}
+
+func TestCreateTimelogsOverlapWithinBatch(t *testing.T) {
+	entry := func(jobID string, start, end int64) *request.CreateTimelogSvcReq {
+		return &request.CreateTimelogSvcReq{JobID: jobID, TimeStart: start, TimeEnd: end, Duration: end - start, Type: "captured"}
+	}
+
+	tests := []struct {
+		name    string
+		batch   []*request.CreateTimelogSvcReq
+		wantErr string
+	}{
+		{
+			name:  "should accept back to back timelogs of one contractor",
+			batch: []*request.CreateTimelogSvcReq{entry("job-1", 0, 100), entry("job-1", 100, 200)},
+		},
+		{
+			name:  "should accept the same interval for different contractors",
+			batch: []*request.CreateTimelogSvcReq{entry("job-1", 0, 100), entry("job-2", 0, 100)},
+		},
+		{
+			name:    "should refuse a duplicate entry",
+			batch:   []*request.CreateTimelogSvcReq{entry("job-1", 0, 100), entry("job-1", 0, 100)},
+			wantErr: "timelogs[1]: timelog overlaps timelog 0 of the batch",
+		},
+		{
+			name:    "should refuse a nested entry",
+			batch:   []*request.CreateTimelogSvcReq{entry("job-1", 0, 100), entry("job-2", 0, 100), entry("job-1", 20, 80)},
+			wantErr: "timelogs[2]: timelog overlaps timelog 0 of the batch",
+		},
+		{
+			name:    "should refuse a partially overlapping entry",
+			batch:   []*request.CreateTimelogSvcReq{entry("job-1", 50, 150), entry("job-1", 0, 100)},
+			wantErr: "timelogs[1]: timelog overlaps timelog 0 of the batch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &stubTimelogRepo{}
+			svc := NewTimelogService(repo, stubJobRepo{}, &stubGenerator{}, postgrestest.Transactor{})
+
+			created, err := svc.CreateTimelogs(context.Background(), tt.batch)
+
+			if tt.wantErr != "" {
+				appErr, ok := apperror.As(err)
+				if assert.True(t, ok, err) {
+					assert.Equal(t, apperror.CodeValidation, appErr.Code)
+					assert.Equal(t, tt.wantErr, appErr.Message)
+				}
+				assert.Empty(t, repo.created)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, created, len(tt.batch))
+		})
+	}
+}
+
+func TestReviewTimelog(t *testing.T) {
+	ctx := context.Background()
+	timelog := func(status domain.TimelogApprovalStatus) *domain.Timelog {
+		timelog := domain.NewTimelog(100, 100, 200, "captured", "job-1-v1")
+		timelog.SCDModel = &scd.SCDModel{ID: "timelog-1", Version: 1, UID: "timelog-1-v1", IsLatest: true}
+		timelog.ApprovalStatus = status
+		return timelog
+	}
+	req := &request.ReviewTimelogSvcReq{TimelogID: "timelog-1", ReviewerID: "company-1"}
+
+	tests := []struct {
+		name       string
+		latest     domain.TimelogApprovalStatus
+		review     func(svc *TimelogService) (*domain.Timelog, error)
+		wantStatus domain.TimelogApprovalStatus
+		wantItem   domain.PaymentLineItemStatus
+		wantCode   apperror.Code
+	}{
+		{
+			name:       "should approve the line item of an approved timelog",
+			latest:     domain.TimelogApprovalStatusPending,
+			review:     func(svc *TimelogService) (*domain.Timelog, error) { return svc.ApproveTimelog(ctx, req) },
+			wantStatus: domain.TimelogApprovalStatusApproved,
+			wantItem:   domain.PaymentLineItemStatusApproved,
+		},
+		{
+			name:       "should void the line item of a rejected timelog",
+			latest:     domain.TimelogApprovalStatusApproved,
+			review:     func(svc *TimelogService) (*domain.Timelog, error) { return svc.RejectTimelog(ctx, req) },
+			wantStatus: domain.TimelogApprovalStatusRejected,
+			wantItem:   domain.PaymentLineItemStatusVoided,
+		},
+		{
+			name:     "should refuse to approve a rejected timelog",
+			latest:   domain.TimelogApprovalStatusRejected,
+			review:   func(svc *TimelogService) (*domain.Timelog, error) { return svc.ApproveTimelog(ctx, req) },
+			wantCode: apperror.CodeInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := &stubGenerator{}
+			svc := NewTimelogService(&stubTimelogRepo{latest: timelog(tt.latest)}, stubJobRepo{}, generator, postgrestest.Transactor{})
+
+			reviewed, err := tt.review(svc)
+
+			if tt.wantCode != "" {
+				assert.True(t, apperror.HasCode(err, tt.wantCode), err)
+				assert.Empty(t, generator.status)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, reviewed.ApprovalStatus)
+			assert.Equal(t, 2, reviewed.GetVersion())
+			assert.Equal(t, tt.wantItem, generator.status)
+		})
+	}
+}
+
+func TestRevertTimelogToVersion(t *testing.T) {
+	timelog := func(version int, status domain.TimelogApprovalStatus) *domain.Timelog {
+		timelog := domain.NewTimelog(100, 100, 200, "captured", "job-1-v1")
+		timelog.SCDModel = &scd.SCDModel{ID: "timelog-1", Version: version, IsLatest: true}
+		timelog.ApprovalStatus = status
+		return timelog
+	}
+
+	tests := []struct {
+		name     string
+		latest   domain.TimelogApprovalStatus
+		reverted domain.TimelogApprovalStatus
+		wantItem domain.PaymentLineItemStatus
+		wantCode apperror.Code
+	}{
+		{
+			name:     "should approve the line item of a revert to an approved version",
+			latest:   domain.TimelogApprovalStatusApproved,
+			reverted: domain.TimelogApprovalStatusApproved,
+			wantItem: domain.PaymentLineItemStatusApproved,
+		},
+		{
+			name:     "should leave the line item of a revert to a pending version unapproved",
+			latest:   domain.TimelogApprovalStatusApproved,
+			reverted: domain.TimelogApprovalStatusPending,
+		},
+		{
+			name:     "should refuse to revert a rejected timelog",
+			latest:   domain.TimelogApprovalStatusRejected,
+			reverted: domain.TimelogApprovalStatusApproved,
+			wantCode: apperror.CodeInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := &stubGenerator{}
+			repo := &stubTimelogRepo{latest: timelog(3, tt.latest), reverted: map[int]*domain.Timelog{2: timelog(2, tt.reverted)}}
+			svc := NewTimelogService(repo, stubJobRepo{}, generator, postgrestest.Transactor{})
+
+			reverted, err := svc.RevertTimelogToVersion(context.Background(), "timelog-1", 2)
+
+			if tt.wantCode != "" {
+				assert.True(t, apperror.HasCode(err, tt.wantCode), err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 4, reverted.GetVersion())
+			assert.Equal(t, tt.reverted, reverted.ApprovalStatus)
+			assert.Equal(t, tt.wantItem, generator.status)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code identifies the kind of failure in the error envelope returned to clients
+type Code string
+
+const (
+	CodeValidation         Code = "VALIDATION_FAILED"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodeForbidden          Code = "FORBIDDEN"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeConflict           Code = "CONFLICT"
+	CodePreconditionFailed Code = "PRECONDITION_FAILED"
+	CodeInvalidTransition  Code = "INVALID_TRANSITION"
+	CodeInternal           Code = "INTERNAL_ERROR"
+)
+
+var codeToStatus = map[Code]int{
+	CodeValidation:         http.StatusBadRequest,
+	CodeUnauthorized:       http.StatusUnauthorized,
+	CodeForbidden:          http.StatusForbidden,
+	CodeNotFound:           http.StatusNotFound,
+	CodeConflict:           http.StatusConflict,
+	CodePreconditionFailed: http.StatusPreconditionFailed,
+	CodeInvalidTransition:  http.StatusConflict,
+	CodeInternal:           http.StatusInternalServerError,
+}
+
+// Error is a domain error whose code decides the HTTP status it is served with
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Status returns the HTTP status code the error is served with
+func (e *Error) Status() int {
+	status, ok := codeToStatus[e.Code]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func Validation(message string) *Error {
+	return New(CodeValidation, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(CodeConflict, message)
+}
+
+func PreconditionFailed(message string) *Error {
+	return New(CodePreconditionFailed, message)
+}
+
+func InvalidTransition(message string) *Error {
+	return New(CodeInvalidTransition, message)
+}
+
+// As returns the domain error wrapped in err, if any
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// HasCode reports whether err wraps a domain error with the given code
+func HasCode(err error, code Code) bool {
+	appErr, ok := As(err)
+	return ok && appErr.Code == code
+}
@@ -0,0 +1,53 @@
+package apperror
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/constants"
+	"github.com/mercor/payment-service/pkg/log"
+)
+
+// Response is the JSON envelope of every error returned by the API
+type Response struct {
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// Abort stops the handler chain and leaves err to Middleware, which writes the error response
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// Middleware writes the error envelope for the last error a handler recorded with Abort.
+// Domain errors are served with the status of their code, any other error is logged and
+// served as an internal error without leaking its message.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		response := Response{
+			Code:      CodeInternal,
+			Message:   http.StatusText(http.StatusInternalServerError),
+			RequestID: c.GetString(constants.HeaderXMercorRequestID),
+		}
+		status := http.StatusInternalServerError
+
+		if appErr, ok := As(err); ok {
+			response.Code = appErr.Code
+			response.Message = err.Error()
+			status = appErr.Status()
+		} else {
+			log.WithError(err).Errorf("request %s %s failed", c.Request.Method, c.FullPath())
+		}
+
+		c.JSON(status, response)
+	}
+}
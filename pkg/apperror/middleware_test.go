@@ -0,0 +1,52 @@
+package apperror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(t *testing.T, err error) (*httptest.ResponseRecorder, Response) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set(constants.HeaderXMercorRequestID, "req_123")
+	})
+	engine.Use(Middleware())
+	engine.GET("/", func(c *gin.Context) {
+		Abort(c, err)
+	})
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var response Response
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return recorder, response
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("should serve a wrapped domain error with the status of its code", func(t *testing.T) {
+		recorder, response := serve(t, fmt.Errorf("finding job: %w", NotFound("record not found")))
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, CodeNotFound, response.Code)
+		assert.Equal(t, "finding job: record not found", response.Message)
+		assert.Equal(t, "req_123", response.RequestID)
+	})
+
+	t.Run("should not leak the message of an unexpected error", func(t *testing.T) {
+		recorder, response := serve(t, errors.New("pq: connection refused"))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.Equal(t, CodeInternal, response.Code)
+		assert.Equal(t, http.StatusText(http.StatusInternalServerError), response.Message)
+	})
+}
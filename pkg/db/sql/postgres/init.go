@@ -16,6 +16,11 @@ func InitializeDBInstance(master DBConfig, slaves *[]DBConfig) *DbCluster {
 	return db
 }
 
+// NewDbCluster serves every read and write from one open connection, for tests and tools that open their own
+func NewDbCluster(db *gorm.DB) *DbCluster {
+	return &DbCluster{master: &Connection{db: db}}
+}
+
 func getDbInstance(master DBConfig, slaves *[]DBConfig) (instance *DbCluster) {
 	slavesCount := len(*slaves)
 	instance = &DbCluster{
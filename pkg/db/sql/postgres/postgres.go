@@ -13,6 +13,11 @@ type Consistency struct {
 }
 
 func (db *DbCluster) GetMasterDB(ctx context.Context) *gorm.DB {
+	// Join the transaction of the unit of work, if any
+	if tx, ok := getTx(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+
 	if val, ok := ctx.Value(constants.Consistency).(*Consistency); ok && val.consistency == constants.EventualConsistency {
 		val.consistency = constants.StrongConsistency
 	}
@@ -21,6 +26,11 @@ func (db *DbCluster) GetMasterDB(ctx context.Context) *gorm.DB {
 }
 
 func (db *DbCluster) GetSlaveDB(ctx context.Context) *gorm.DB {
+	// Reads inside a unit of work must see its own writes
+	if tx, ok := getTx(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+
 	//if val, ok := ctx.Value(constants.Consistency).(*Consistency); ok && val.consistency == constants.StrongConsistency {
 	//	return db.getMaster(ctx)
 	//}
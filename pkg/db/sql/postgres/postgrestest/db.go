@@ -0,0 +1,79 @@
+package postgrestest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	pgdriver "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DatabaseURLEnv names the environment variable holding the URL of a PostgreSQL database the tests may write to,
+// such as postgres://localhost:5433/crud?user=admin&password=admin&sslmode=disable for the docker-compose database
+const DatabaseURLEnv = "TEST_DATABASE_URL"
+
+// Open connects to the test database in a schema of its own that is dropped when the test ends.
+// The test is skipped when DatabaseURLEnv is not set.
+func Open(t *testing.T) *postgres.DbCluster {
+	t.Helper()
+
+	rawURL := os.Getenv(DatabaseURLEnv)
+	if rawURL == "" {
+		t.Skipf("%s is not set", DatabaseURLEnv)
+	}
+
+	admin := open(t, rawURL)
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("failed to create schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	schemaURL, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("%s is not a URL: %v", DatabaseURLEnv, err)
+	}
+	query := schemaURL.Query()
+	query.Set("search_path", schema)
+	schemaURL.RawQuery = query.Encode()
+
+	return postgres.NewDbCluster(open(t, schemaURL.String()))
+}
+
+// Exec runs the statements, such as the DDL of the tables a test needs, and fails the test on an error
+func Exec(t *testing.T, db *postgres.DbCluster, statements ...string) {
+	t.Helper()
+
+	for _, statement := range statements {
+		if err := db.GetMasterDB(context.Background()).Exec(statement).Error; err != nil {
+			t.Fatalf("failed to execute %q: %v", statement, err)
+		}
+	}
+}
+
+func open(t *testing.T, dsn string) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(pgdriver.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get the connection pool of the test database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = sqlDB.Close()
+	})
+	return db
+}
@@ -0,0 +1,11 @@
+package postgrestest
+
+import "context"
+
+// Transactor runs every unit of work directly in the context it is given, without a transaction
+type Transactor struct{}
+
+// RunInTx runs fn with ctx and returns its error
+func (Transactor) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/mercor/payment-service/constants"
+	"gorm.io/gorm"
+)
+
+// Transactor runs a unit of work in a single database transaction
+type Transactor interface {
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// RunInTx runs fn in a transaction on master and commits it when fn returns nil.
+// The context passed to fn carries the transaction, every GetMasterDB/GetSlaveDB call made with it
+// joins the transaction. A nested RunInTx runs in a savepoint of the outer transaction, so its
+// failure only rolls back its own changes unless the outer fn returns the error as well.
+func (db *DbCluster) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent := db.getMaster(ctx)
+	if tx, ok := getTx(ctx); ok {
+		parent = tx.WithContext(ctx)
+	}
+
+	return parent.Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, constants.DBTransaction, tx))
+	})
+}
+
+// getTx returns the transaction started by RunInTx that ctx carries, if any
+func getTx(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(constants.DBTransaction).(*gorm.DB)
+	return tx, ok && tx != nil
+}
@@ -0,0 +1,129 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mercor/payment-service/constants"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/stretchr/testify/assert"
+)
+
+var errRollback = errors.New("rollback")
+
+func newTestCluster(t *testing.T) *postgres.DbCluster {
+	db := postgrestest.Open(t)
+	postgrestest.Exec(t, db, "CREATE TABLE tx_record (name VARCHAR(255) PRIMARY KEY)")
+	return db
+}
+
+func insert(ctx context.Context, db *postgres.DbCluster, name string) error {
+	return db.GetMasterDB(ctx).Exec("INSERT INTO tx_record (name) VALUES (?)", name).Error
+}
+
+// names returns the names ctx sees on master and on the slaves, in order
+func names(t *testing.T, ctx context.Context, db *postgres.DbCluster) ([]string, []string) {
+	t.Helper()
+
+	var master, slave []string
+	assert.NoError(t, db.GetMasterDB(ctx).Raw("SELECT name FROM tx_record ORDER BY name").Scan(&master).Error)
+	slaveCtx := context.WithValue(ctx, constants.DBPreference, constants.SlaveDB)
+	assert.NoError(t, db.GetSlaveDB(slaveCtx).Raw("SELECT name FROM tx_record ORDER BY name").Scan(&slave).Error)
+	return master, slave
+}
+
+func TestRunInTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should commit the work of fn", func(t *testing.T) {
+		db := newTestCluster(t)
+
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			return insert(ctx, db, "a")
+		})
+
+		assert.NoError(t, err)
+		master, _ := names(t, ctx, db)
+		assert.Equal(t, []string{"a"}, master)
+	})
+
+	t.Run("should roll back the work of fn when it fails", func(t *testing.T) {
+		db := newTestCluster(t)
+
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			if err := insert(ctx, db, "a"); err != nil {
+				return err
+			}
+			return errRollback
+		})
+
+		assert.ErrorIs(t, err, errRollback)
+		master, _ := names(t, ctx, db)
+		assert.Empty(t, master)
+	})
+
+	t.Run("should roll back a failed nested transaction only when the outer one commits", func(t *testing.T) {
+		db := newTestCluster(t)
+
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			if err := insert(ctx, db, "outer"); err != nil {
+				return err
+			}
+			innerErr := db.RunInTx(ctx, func(ctx context.Context) error {
+				if err := insert(ctx, db, "inner"); err != nil {
+					return err
+				}
+				return errRollback
+			})
+			assert.ErrorIs(t, innerErr, errRollback)
+			return insert(ctx, db, "after")
+		})
+
+		assert.NoError(t, err)
+		master, _ := names(t, ctx, db)
+		assert.Equal(t, []string{"after", "outer"}, master)
+	})
+
+	t.Run("should discard the work of a nested transaction when the outer one fails", func(t *testing.T) {
+		db := newTestCluster(t)
+
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			if err := insert(ctx, db, "outer"); err != nil {
+				return err
+			}
+			if err := db.RunInTx(ctx, func(ctx context.Context) error {
+				return insert(ctx, db, "inner")
+			}); err != nil {
+				return err
+			}
+			return errRollback
+		})
+
+		assert.ErrorIs(t, err, errRollback)
+		master, _ := names(t, ctx, db)
+		assert.Empty(t, master)
+	})
+
+	t.Run("should read the uncommitted work of the transaction that the context carries only", func(t *testing.T) {
+		db := newTestCluster(t)
+
+		err := db.RunInTx(ctx, func(txCtx context.Context) error {
+			if err := insert(txCtx, db, "a"); err != nil {
+				return err
+			}
+
+			master, slave := names(t, txCtx, db)
+			assert.Equal(t, []string{"a"}, master)
+			assert.Equal(t, []string{"a"}, slave)
+
+			master, slave = names(t, ctx, db)
+			assert.Empty(t, master)
+			assert.Empty(t, slave)
+			return nil
+		})
+
+		assert.NoError(t, err)
+	})
+}
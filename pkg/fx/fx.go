@@ -0,0 +1,83 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/mercor/payment-service/pkg/config"
+	"github.com/mercor/payment-service/pkg/money"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrRateNotFound is returned when no rate is known for a currency pair
+var ErrRateNotFound = apperror.Validation("fx rate not found")
+
+// Provider returns exchange rates, implementations can read them from a file, a database or an external service
+type Provider interface {
+	// Rate returns the units of quote one unit of base buys
+	Rate(ctx context.Context, base, quote string) (money.Decimal, error)
+}
+
+// FileProvider serves the rates of a YAML file that maps BASE/QUOTE pairs to rates
+type FileProvider struct {
+	rates map[string]money.Decimal
+}
+
+var (
+	localProvider     *FileProvider
+	localProviderErr  error
+	localProviderOnce sync.Once
+)
+
+// NewLocalProvider returns the FileProvider of the file configured at fx.ratesFile
+func NewLocalProvider(ctx context.Context) (Provider, error) {
+	localProviderOnce.Do(func() {
+		localProvider, localProviderErr = NewFileProvider(config.GetString(ctx, "fx.ratesFile"))
+	})
+	return localProvider, localProviderErr
+}
+
+func NewFileProvider(path string) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fx rates file: %w", err)
+	}
+
+	var file struct {
+		Rates map[string]string `yaml:"rates"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse fx rates file: %w", err)
+	}
+
+	rates := make(map[string]money.Decimal, len(file.Rates))
+	for pair, value := range file.Rates {
+		rate, err := money.Parse(value)
+		if err != nil || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid fx rate %q for %s", value, pair)
+		}
+		rates[strings.ToUpper(pair)] = rate
+	}
+
+	return &FileProvider{rates: rates}, nil
+}
+
+func (p *FileProvider) Rate(ctx context.Context, base, quote string) (money.Decimal, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if base == quote {
+		return money.NewFromInt(1), nil
+	}
+
+	if rate, ok := p.rates[base+"/"+quote]; ok {
+		return rate, nil
+	}
+	if inverse, ok := p.rates[quote+"/"+base]; ok {
+		return money.NewFromInt(1).Div(inverse, money.Scale, money.RoundHalfEven)
+	}
+
+	return money.Decimal{}, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+}
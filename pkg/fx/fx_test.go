@@ -0,0 +1,47 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mercor/payment-service/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fx_rates.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("rates:\n  EUR/USD: \"1.25\"\n"), 0o600))
+
+	provider, err := NewFileProvider(path)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("should return the listed rate", func(t *testing.T) {
+		rate, err := provider.Rate(ctx, "eur", "USD")
+
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("1.25"), rate)
+	})
+
+	t.Run("should derive the inverse of a listed rate", func(t *testing.T) {
+		rate, err := provider.Rate(ctx, "USD", "EUR")
+
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("0.8"), rate)
+	})
+
+	t.Run("should return 1 for the same currency", func(t *testing.T) {
+		rate, err := provider.Rate(ctx, "INR", "INR")
+
+		assert.NoError(t, err)
+		assert.Equal(t, money.NewFromInt(1), rate)
+	})
+
+	t.Run("should fail for an unknown pair", func(t *testing.T) {
+		_, err := provider.Rate(ctx, "USD", "JPY")
+
+		assert.ErrorIs(t, err, ErrRateNotFound)
+	})
+}
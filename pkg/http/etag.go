@@ -0,0 +1,42 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// VersionETag returns a strong ETag carrying the version of a record
+func VersionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseVersionETag extracts the version from an If-Match header value.
+// ok is false when the header is empty, a weak or wildcard ETag is rejected.
+func ParseVersionETag(header string) (version int, ok bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false, nil
+	}
+
+	if header == "*" || strings.HasPrefix(header, "W/") || strings.Contains(header, ",") {
+		return 0, false, errors.New("If-Match must carry a single strong ETag")
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		unquoted = header
+	}
+
+	version, err = strconv.Atoi(unquoted)
+	if err != nil {
+		return 0, false, errors.New("If-Match does not carry a valid version")
+	}
+
+	return version, true, nil
+}
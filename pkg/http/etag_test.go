@@ -0,0 +1,59 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionETag(t *testing.T) {
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{version: 1, want: `"1"`},
+		{version: 42, want: `"42"`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, VersionETag(tt.version))
+	}
+}
+
+func TestParseVersionETag(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantVersion int
+		wantOK      bool
+		wantErr     bool
+	}{
+		{name: "should report a missing header", header: ""},
+		{name: "should report a blank header as missing", header: "  "},
+		{name: "should read a strong ETag", header: `"3"`, wantVersion: 3, wantOK: true},
+		{name: "should read a strong ETag surrounded by spaces", header: ` "3" `, wantVersion: 3, wantOK: true},
+		{name: "should read a bare version", header: "3", wantVersion: 3, wantOK: true},
+		{name: "should read the ETag of VersionETag", header: VersionETag(17), wantVersion: 17, wantOK: true},
+		{name: "should reject a wildcard", header: "*", wantErr: true},
+		{name: "should reject a weak ETag", header: `W/"3"`, wantErr: true},
+		{name: "should reject a list of ETags", header: `"3", "4"`, wantErr: true},
+		{name: "should reject a list of one ETag and a wildcard", header: `"3",*`, wantErr: true},
+		{name: "should reject an ETag that is not a version", header: `"abc"`, wantErr: true},
+		{name: "should reject an unterminated ETag", header: `"3`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, ok, err := ParseVersionETag(tt.header)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.False(t, ok)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package jwks
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mercor/payment-service/pkg/log"
+)
+
+var (
+	// ErrKeyNotFound is returned when the key set has no usable key with the requested kid
+	ErrKeyNotFound = errors.New("jwks: key not found")
+	// ErrNoKeys is returned when the JWKS document of the source has no usable key
+	ErrNoKeys = errors.New("jwks: no keys")
+)
+
+const (
+	// DefaultRefreshInterval is how long fetched keys are used before the source is read again
+	DefaultRefreshInterval = 15 * time.Minute
+	// minRefetchInterval limits how often an unknown kid can make the key set read its source
+	minRefetchInterval = 30 * time.Second
+	fetchTimeout       = 10 * time.Second
+)
+
+// KeySet serves the RSA keys of a JWKS document read from a file path or an http(s) URL.
+// Keys are cached for the refresh interval, a kid missing from the cache reads the source again
+// so rotated keys are picked up before the interval ends.
+type KeySet struct {
+	source   string
+	refresh  time.Duration
+	client   *http.Client
+	now      func() time.Time
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+}
+
+// NewKeySet returns the key set of source, refresh defaults to DefaultRefreshInterval
+func NewKeySet(source string, refresh time.Duration) (*KeySet, error) {
+	if source == "" {
+		return nil, errors.New("jwks: no source configured")
+	}
+	if refresh <= 0 {
+		refresh = DefaultRefreshInterval
+	}
+	return &KeySet{
+		source:  source,
+		refresh: refresh,
+		client:  &http.Client{Timeout: fetchTimeout},
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}, nil
+}
+
+// Refresh reads the source now and keeps its keys, it fails when the source cannot be read or has no usable key
+func (s *KeySet) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	keys, err := s.fetch(ctx)
+	s.attemptedAt = now
+	s.fetchErr = err
+	if err != nil {
+		return err
+	}
+	s.keys, s.fetchedAt = keys, now
+	return nil
+}
+
+// StartRefresh refreshes the keys every refresh interval until Close, so keys removed from the source stop being
+// accepted without waiting for a request to read it. A failed refresh is logged and keeps the previous keys.
+func (s *KeySet) StartRefresh() {
+	ticker := time.NewTicker(s.refresh)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				if err := s.Refresh(context.Background()); err != nil {
+					log.Errorf("Unable to refresh the JWKS :: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the refresh started by StartRefresh
+func (s *KeySet) Close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Key returns the key with the kid
+func (s *KeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	fresh := !s.fetchedAt.IsZero() && s.now().Sub(s.fetchedAt) < s.refresh
+	s.mu.RUnlock()
+	if ok && fresh {
+		return key, nil
+	}
+
+	if err := s.load(ctx, ok); err != nil {
+		// Keep serving known keys while the source is unavailable
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok = s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+	}
+	return key, nil
+}
+
+// load reads the source again unless it was read too recently, known tells whether the caller only needs a refresh
+func (s *KeySet) load(ctx context.Context, known bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Unknown kids and failing sources must not hammer the source, and another request may have refreshed meanwhile
+	now := s.now()
+	if !s.attemptedAt.IsZero() && now.Sub(s.attemptedAt) < minRefetchInterval {
+		return s.fetchErr
+	}
+	if known && now.Sub(s.fetchedAt) < s.refresh {
+		return nil
+	}
+
+	keys, err := s.fetch(ctx)
+	s.attemptedAt = now
+	s.fetchErr = err
+	if err != nil {
+		return err
+	}
+	s.keys, s.fetchedAt = keys, now
+	return nil
+}
+
+func (s *KeySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	data, err := s.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: failed to read %s: %w", s.source, err)
+	}
+	keys, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: failed to parse %s: %w", s.source, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoKeys, s.source)
+	}
+	return keys, nil
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(s.source)
+	}
+
+	// The keys are shared by every request, the one that happens to fetch them must not cancel the fetch for the rest
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Parse returns the RSA signing keys of a JWKS document by kid, keys of other types or uses are skipped
+func Parse(data []byte) (map[string]*rsa.PublicKey, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		if jwk.Kid == "" {
+			return nil, errors.New("RSA key without kid")
+		}
+		key, err := rsaPublicKey(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func rsaPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA parameters")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,269 @@
+package jwks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func jwksDocument(t *testing.T, keys map[string]*rsa.PrivateKey) []byte {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	for kid, key := range keys {
+		doc.Keys = append(doc.Keys, jsonWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	data, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	return data
+}
+
+func writeJWKS(t *testing.T, path string, keys map[string]*rsa.PrivateKey) {
+	assert.NoError(t, os.WriteFile(path, jwksDocument(t, keys), 0o600))
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.RegisteredClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return signed
+}
+
+func TestVerifier(t *testing.T) {
+	key := generateKey(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]*rsa.PrivateKey{"key-1": key})
+
+	keys, err := NewKeySet(path, time.Minute)
+	assert.NoError(t, err)
+	verifier, err := NewVerifier(keys, []string{"https://issuer-a", "https://issuer-b"}, []string{"payment-service"}, 0)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	valid := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Issuer:    "https://issuer-b",
+			Audience:  jwt.ClaimStrings{"other-service", "payment-service"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			NotBefore: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		}
+	}
+
+	t.Run("should accept a token of any configured issuer for a configured audience", func(t *testing.T) {
+		claims := &jwt.RegisteredClaims{}
+		err := verifier.Verify(ctx, sign(t, key, "key-1", valid()), claims)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://issuer-b", claims.Issuer)
+	})
+
+	t.Run("should reject an unknown issuer", func(t *testing.T) {
+		claims := valid()
+		claims.Issuer = "https://issuer-c"
+
+		err := verifier.Verify(ctx, sign(t, key, "key-1", claims), &jwt.RegisteredClaims{})
+
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+	})
+
+	t.Run("should reject a token for another audience", func(t *testing.T) {
+		claims := valid()
+		claims.Audience = jwt.ClaimStrings{"other-service"}
+
+		err := verifier.Verify(ctx, sign(t, key, "key-1", claims), &jwt.RegisteredClaims{})
+
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("should reject an expired token", func(t *testing.T) {
+		claims := valid()
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+		err := verifier.Verify(ctx, sign(t, key, "key-1", claims), &jwt.RegisteredClaims{})
+
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("should reject a token without exp", func(t *testing.T) {
+		claims := valid()
+		claims.ExpiresAt = nil
+
+		err := verifier.Verify(ctx, sign(t, key, "key-1", claims), &jwt.RegisteredClaims{})
+
+		assert.ErrorIs(t, err, jwt.ErrTokenRequiredClaimMissing)
+	})
+
+	t.Run("should reject a token that is not valid yet", func(t *testing.T) {
+		claims := valid()
+		claims.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+		err := verifier.Verify(ctx, sign(t, key, "key-1", claims), &jwt.RegisteredClaims{})
+
+		assert.ErrorIs(t, err, jwt.ErrTokenNotValidYet)
+	})
+
+	t.Run("should reject a token signed by a key that is not in the set", func(t *testing.T) {
+		err := verifier.Verify(ctx, sign(t, generateKey(t), "key-1", valid()), &jwt.RegisteredClaims{})
+
+		assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+
+	t.Run("should reject an HMAC token", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, valid())
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString([]byte("secret"))
+		assert.NoError(t, err)
+
+		err = verifier.Verify(ctx, signed, &jwt.RegisteredClaims{})
+
+		assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey, newKey := generateKey(t), generateKey(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]*rsa.PrivateKey{"old": oldKey})
+
+	now := time.Now()
+	keys, err := NewKeySet(path, time.Hour)
+	assert.NoError(t, err)
+	keys.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, err := keys.Key(ctx, "old")
+	assert.NoError(t, err)
+	assert.Equal(t, &oldKey.PublicKey, key)
+
+	writeJWKS(t, path, map[string]*rsa.PrivateKey{"new": newKey})
+
+	t.Run("should not read the source again right after reading it", func(t *testing.T) {
+		_, err := keys.Key(ctx, "new")
+
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("should pick up a rotated key for an unknown kid", func(t *testing.T) {
+		now = now.Add(minRefetchInterval)
+
+		key, err := keys.Key(ctx, "new")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &newKey.PublicKey, key)
+	})
+
+	t.Run("should keep serving cached keys while the source fails", func(t *testing.T) {
+		assert.NoError(t, os.Remove(path))
+		now = now.Add(2 * time.Hour)
+
+		key, err := keys.Key(ctx, "new")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &newKey.PublicKey, key)
+	})
+}
+
+func TestKeySetFromURL(t *testing.T) {
+	key := generateKey(t)
+	document := jwksDocument(t, map[string]*rsa.PrivateKey{"key-1": key})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(document)
+	}))
+	defer server.Close()
+
+	keys, err := NewKeySet(server.URL, time.Minute)
+	assert.NoError(t, err)
+
+	got, err := keys.Key(context.Background(), "key-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &key.PublicKey, got)
+}
+
+func TestKeySetRefresh(t *testing.T) {
+	oldKey, newKey := generateKey(t), generateKey(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	ctx := context.Background()
+
+	hasKeys := func(keys *KeySet, kids ...string) bool {
+		keys.mu.RLock()
+		defer keys.mu.RUnlock()
+		if len(keys.keys) != len(kids) {
+			return false
+		}
+		for _, kid := range kids {
+			if _, ok := keys.keys[kid]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	t.Run("should fail for a source without keys", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte(`{"keys": []}`), 0o600))
+		keys, err := NewKeySet(path, time.Hour)
+		assert.NoError(t, err)
+
+		err = keys.Refresh(ctx)
+
+		assert.ErrorIs(t, err, ErrNoKeys)
+	})
+
+	t.Run("should keep the previous keys when the source fails", func(t *testing.T) {
+		writeJWKS(t, path, map[string]*rsa.PrivateKey{"old": oldKey})
+		keys, err := NewKeySet(path, time.Hour)
+		assert.NoError(t, err)
+		assert.NoError(t, keys.Refresh(ctx))
+
+		assert.NoError(t, os.WriteFile(path, []byte(`{"keys": []}`), 0o600))
+		err = keys.Refresh(ctx)
+
+		assert.ErrorIs(t, err, ErrNoKeys)
+		assert.True(t, hasKeys(keys, "old"))
+	})
+
+	t.Run("should drop removed keys every refresh interval until closed", func(t *testing.T) {
+		writeJWKS(t, path, map[string]*rsa.PrivateKey{"old": oldKey})
+		keys, err := NewKeySet(path, 10*time.Millisecond)
+		assert.NoError(t, err)
+		assert.NoError(t, keys.Refresh(ctx))
+
+		keys.StartRefresh()
+		writeJWKS(t, path, map[string]*rsa.PrivateKey{"new": newKey})
+
+		assert.Eventually(t, func() bool { return hasKeys(keys, "new") }, time.Second, 5*time.Millisecond)
+
+		keys.Close()
+		// Let a refresh that was running when the key set closed finish
+		time.Sleep(20 * time.Millisecond)
+		writeJWKS(t, path, map[string]*rsa.PrivateKey{"old": oldKey})
+		time.Sleep(50 * time.Millisecond)
+
+		assert.True(t, hasKeys(keys, "new"))
+	})
+}
@@ -0,0 +1,93 @@
+package jwks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingMethods are the algorithms tokens may be signed with, all of them verify with the RSA keys of the key set
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+
+// Verifier checks the signature of a token against a key set and its exp, nbf, iss and aud claims
+type Verifier struct {
+	keys      *KeySet
+	issuers   []string
+	audiences []string
+	leeway    time.Duration
+}
+
+// NewVerifier returns a verifier accepting tokens of any of the issuers for any of the audiences,
+// leeway allows for clock skew when checking exp and nbf
+func NewVerifier(keys *KeySet, issuers, audiences []string, leeway time.Duration) (*Verifier, error) {
+	if len(issuers) == 0 {
+		return nil, errors.New("jwks: no token issuers configured")
+	}
+	if len(audiences) == 0 {
+		return nil, errors.New("jwks: no token audiences configured")
+	}
+	return &Verifier{keys: keys, issuers: issuers, audiences: audiences, leeway: leeway}, nil
+}
+
+// Keys returns the key set the verifier checks signatures against
+func (v *Verifier) Keys() *KeySet {
+	return v.keys
+}
+
+// Close stops the periodic refresh of the key set, if any
+func (v *Verifier) Close() {
+	if v.keys != nil {
+		v.keys.Close()
+	}
+}
+
+// Verify parses the token into claims and returns an error unless it is valid
+func (v *Verifier) Verify(ctx context.Context, token string, claims jwt.Claims) error {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.leeway),
+	)
+
+	_, err := parser.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid")
+		}
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return err
+	}
+
+	issuer, err := claims.GetIssuer()
+	if err != nil {
+		return err
+	}
+	if !contains(v.issuers, issuer) {
+		return fmt.Errorf("%w: unknown issuer %q", jwt.ErrTokenInvalidIssuer, issuer)
+	}
+
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return err
+	}
+	for _, audience := range audiences {
+		if contains(v.audiences, audience) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no accepted audience in %v", jwt.ErrTokenInvalidAudience, []string(audiences))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
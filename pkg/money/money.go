@@ -0,0 +1,341 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Scale is the number of decimal places a Decimal keeps
+	Scale = 8
+	// CentPlaces is the number of decimal places of the DECIMAL(10,2) money columns
+	CentPlaces = 2
+)
+
+var (
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	ErrTooPrecise     = fmt.Errorf("decimal has more than %d decimal places", Scale)
+	ErrDivisionByZero = errors.New("division by zero")
+	ErrOverflow       = fmt.Errorf("decimal out of range [%s, %s]", Decimal{units: math.MinInt64}, Decimal{units: math.MaxInt64})
+)
+
+// RoundingMode decides where a value that lies between two representable values ends up
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest value and away from zero on a tie
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest value and to the even neighbour on a tie
+	RoundHalfEven
+	// RoundDown rounds toward zero
+	RoundDown
+	// RoundUp rounds away from zero
+	RoundUp
+)
+
+var pow10 = [Scale + 1]int64{1, 10, 100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000}
+
+// Decimal is an exact fixed point number with Scale decimal places, its zero value is 0.
+// Decimals are comparable with ==.
+type Decimal struct {
+	units int64
+}
+
+// New returns value × 10^-places, places must be between 0 and Scale and the value must fit, for constants
+func New(value int64, places int32) Decimal {
+	if places < 0 || places > Scale {
+		panic(fmt.Sprintf("money: places %d out of range [0, %d]", places, Scale))
+	}
+	d, err := fromBig(new(big.Int).Mul(big.NewInt(value), big.NewInt(pow10[Scale-places])))
+	if err != nil {
+		panic(fmt.Sprintf("money: %d × 10^-%d: %v", value, places, err))
+	}
+	return d
+}
+
+// NewFromInt returns the whole number value
+func NewFromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// Parse reads a plain decimal such as "-12.50"
+func Parse(s string) (Decimal, error) {
+	raw := strings.TrimSpace(s)
+	negative := strings.HasPrefix(raw, "-")
+	if negative || strings.HasPrefix(raw, "+") {
+		raw = raw[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(raw, ".")
+	if whole == "" && fraction == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > Scale {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrTooPrecise, s)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", Scale-len(fraction))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+	}
+
+	units, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	if negative {
+		units = -units
+	}
+	return Decimal{units: units}, nil
+}
+
+// MustParse is Parse that panics on an invalid decimal, for constants
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Sum adds up the values, it returns ErrOverflow when a partial sum doesn't fit
+func Sum(values ...Decimal) (Decimal, error) {
+	var total Decimal
+	for _, value := range values {
+		var err error
+		if total, err = total.Add(value); err != nil {
+			return Decimal{}, err
+		}
+	}
+	return total, nil
+}
+
+// Add returns d + other or ErrOverflow when the sum doesn't fit
+func (d Decimal) Add(other Decimal) (Decimal, error) {
+	sum := d.units + other.units
+	if (other.units > 0 && sum < d.units) || (other.units < 0 && sum > d.units) {
+		return Decimal{}, ErrOverflow
+	}
+	return Decimal{units: sum}, nil
+}
+
+// Sub returns d - other or ErrOverflow when the difference doesn't fit
+func (d Decimal) Sub(other Decimal) (Decimal, error) {
+	diff := d.units - other.units
+	if (other.units > 0 && diff > d.units) || (other.units < 0 && diff < d.units) {
+		return Decimal{}, ErrOverflow
+	}
+	return Decimal{units: diff}, nil
+}
+
+// Neg returns -d or ErrOverflow for the smallest Decimal, which has no positive counterpart
+func (d Decimal) Neg() (Decimal, error) {
+	if d.units == math.MinInt64 {
+		return Decimal{}, ErrOverflow
+	}
+	return Decimal{units: -d.units}, nil
+}
+
+// Mul returns d × other rounded to places decimal places with the given mode, or ErrOverflow when it doesn't fit
+func (d Decimal) Mul(other Decimal, places int32, mode RoundingMode) (Decimal, error) {
+	num := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(other.units))
+	return fromRatio(num, big.NewInt(pow10[Scale]), places, mode)
+}
+
+// MulRatio returns d × num / den rounded to places decimal places with the given mode,
+// the product is rounded once so no precision is lost in between
+func (d Decimal) MulRatio(num, den int64, places int32, mode RoundingMode) (Decimal, error) {
+	if den == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(num))
+	return fromRatio(product, big.NewInt(den), places, mode)
+}
+
+// Div returns d / other rounded to places decimal places with the given mode
+func (d Decimal) Div(other Decimal, places int32, mode RoundingMode) (Decimal, error) {
+	if other.units == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+	num := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(pow10[Scale]))
+	return fromRatio(num, big.NewInt(other.units), places, mode)
+}
+
+// Round returns d rounded to places decimal places with the given mode, or ErrOverflow when rounding away from zero
+// leaves the range
+func (d Decimal) Round(places int32, mode RoundingMode) (Decimal, error) {
+	return fromRatio(big.NewInt(d.units), big.NewInt(1), places, mode)
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d.units == other.units
+}
+
+func (d Decimal) Sign() int {
+	return d.Cmp(Decimal{})
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// InexactFloat64 returns the nearest float64, it must not be used for calculations
+func (d Decimal) InexactFloat64() float64 {
+	return float64(d.units) / float64(pow10[Scale])
+}
+
+// String returns d without trailing zeros, such as "12.5"
+func (d Decimal) String() string {
+	s := d.StringFixed(Scale)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed returns d with exactly places decimal places, rounding half up when it has more
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 || places > Scale {
+		panic(fmt.Sprintf("money: places %d out of range [0, %d]", places, Scale))
+	}
+	units := roundRatio(big.NewInt(d.units), big.NewInt(1), places, RoundHalfUp)
+
+	sign := ""
+	if units.Sign() < 0 {
+		sign = "-"
+	}
+	abs := units.Abs(units).String()
+	abs = strings.Repeat("0", max(0, Scale+1-len(abs))) + abs
+
+	whole, fraction := abs[:len(abs)-Scale], abs[len(abs)-Scale:]
+	if places == 0 {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction[:places]
+}
+
+// MarshalJSON writes d as a JSON number so no precision is lost on the way to the client
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or a string holding a decimal
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	parsed, err := Parse(strings.Trim(s, `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Scan reads a DECIMAL column
+func (d *Decimal) Scan(value interface{}) error {
+	var (
+		parsed Decimal
+		err    error
+	)
+
+	switch v := value.(type) {
+	case nil:
+		parsed = Decimal{}
+	case []byte:
+		parsed, err = Parse(string(v))
+	case string:
+		parsed, err = Parse(v)
+	case int64:
+		parsed, err = fromBig(new(big.Int).Mul(big.NewInt(v), big.NewInt(pow10[Scale])))
+	case float64:
+		parsed, err = Parse(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		err = fmt.Errorf("%w: cannot scan %T", ErrInvalidDecimal, value)
+	}
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Value writes d to a DECIMAL column
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// fromRatio returns num / den, both in units, rounded to places decimal places with the given mode,
+// or ErrOverflow when the result doesn't fit
+func fromRatio(num, den *big.Int, places int32, mode RoundingMode) (Decimal, error) {
+	return fromBig(roundRatio(num, den, places, mode))
+}
+
+// fromBig returns the Decimal of units or ErrOverflow when they don't fit
+func fromBig(units *big.Int) (Decimal, error) {
+	if !units.IsInt64() {
+		return Decimal{}, ErrOverflow
+	}
+	return Decimal{units: units.Int64()}, nil
+}
+
+// roundRatio returns num / den, both in units, rounded to places decimal places with the given mode, in units
+func roundRatio(num, den *big.Int, places int32, mode RoundingMode) *big.Int {
+	if places < 0 || places > Scale {
+		panic(fmt.Sprintf("money: places %d out of range [0, %d]", places, Scale))
+	}
+
+	// Divide down to a multiple of 10^-places and round the remainder
+	step := new(big.Int).Mul(den, big.NewInt(pow10[Scale-places]))
+	quo, rem := new(big.Int).QuoRem(num, step, new(big.Int))
+
+	if rem.Sign() != 0 {
+		negative := (num.Sign() < 0) != (step.Sign() < 0)
+		half := new(big.Int).Abs(rem)
+		half.Mul(half, big.NewInt(2))
+		tie := half.CmpAbs(step)
+
+		away := false
+		switch mode {
+		case RoundHalfUp:
+			away = tie >= 0
+		case RoundHalfEven:
+			away = tie > 0 || (tie == 0 && quo.Bit(0) == 1)
+		case RoundUp:
+			away = true
+		case RoundDown:
+			away = false
+		}
+
+		if away {
+			if negative {
+				quo.Sub(quo, big.NewInt(1))
+			} else {
+				quo.Add(quo, big.NewInt(1))
+			}
+		}
+	}
+
+	return quo.Mul(quo, big.NewInt(pow10[Scale-places]))
+}
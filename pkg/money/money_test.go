@@ -0,0 +1,170 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should read decimals exactly", func(t *testing.T) {
+		assert.Equal(t, New(1250, 2), MustParse("12.50"))
+		assert.Equal(t, New(-5, 1), MustParse("-0.5"))
+		assert.Equal(t, NewFromInt(7), MustParse("7"))
+	})
+
+	t.Run("should reject values that are not plain decimals", func(t *testing.T) {
+		for _, s := range []string{"", "1e3", "12.5.0", "abc", "-+1", "0.123456789"} {
+			_, err := Parse(s)
+			assert.Error(t, err, s)
+		}
+	})
+}
+
+func TestSumDoesNotDrift(t *testing.T) {
+	items := make([]Decimal, 1000)
+	for i := range items {
+		items[i] = MustParse("0.10")
+	}
+
+	total, err := Sum(items...)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "100", total.String())
+}
+
+func TestRound(t *testing.T) {
+	cases := []struct {
+		value string
+		mode  RoundingMode
+		want  string
+	}{
+		{"2.345", RoundHalfUp, "2.35"},
+		{"-2.345", RoundHalfUp, "-2.35"},
+		{"2.345", RoundHalfEven, "2.34"},
+		{"2.355", RoundHalfEven, "2.36"},
+		{"2.349", RoundDown, "2.34"},
+		{"-2.349", RoundDown, "-2.34"},
+		{"2.341", RoundUp, "2.35"},
+		{"-2.341", RoundUp, "-2.35"},
+	}
+
+	for _, c := range cases {
+		rounded, err := MustParse(c.value).Round(2, c.mode)
+
+		assert.NoError(t, err)
+		assert.Equal(t, c.want, rounded.StringFixed(2), "%s %d", c.value, c.mode)
+	}
+}
+
+func TestMulRatio(t *testing.T) {
+	// 20 minutes at 45.00 an hour
+	amount, err := MustParse("45.00").MulRatio(20*60*1000, 60*60*1000, 2, RoundHalfUp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "15.00", amount.StringFixed(2))
+
+	_, err = MustParse("45.00").MulRatio(1, 0, 2, RoundHalfUp)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+}
+
+func TestOverflow(t *testing.T) {
+	maxDecimal := Decimal{units: math.MaxInt64}
+	minDecimal := Decimal{units: math.MinInt64}
+	smallest := Decimal{units: 1}
+
+	t.Run("should add and subtract up to the bounds", func(t *testing.T) {
+		sum, err := maxDecimal.Sub(smallest)
+		assert.NoError(t, err)
+		sum, err = sum.Add(smallest)
+		assert.NoError(t, err)
+		assert.Equal(t, maxDecimal, sum)
+
+		diff, err := minDecimal.Add(smallest)
+		assert.NoError(t, err)
+		diff, err = diff.Sub(smallest)
+		assert.NoError(t, err)
+		assert.Equal(t, minDecimal, diff)
+	})
+
+	t.Run("should refuse results beyond the bounds", func(t *testing.T) {
+		tests := []struct {
+			name string
+			op   func() (Decimal, error)
+		}{
+			{name: "max + smallest", op: func() (Decimal, error) { return maxDecimal.Add(smallest) }},
+			{name: "min + -smallest", op: func() (Decimal, error) { return minDecimal.Add(Decimal{units: -1}) }},
+			{name: "min - smallest", op: func() (Decimal, error) { return minDecimal.Sub(smallest) }},
+			{name: "max - -smallest", op: func() (Decimal, error) { return maxDecimal.Sub(Decimal{units: -1}) }},
+			{name: "0 - min", op: func() (Decimal, error) { return Decimal{}.Sub(minDecimal) }},
+			{name: "-min", op: func() (Decimal, error) { return minDecimal.Neg() }},
+			{name: "sum past max", op: func() (Decimal, error) { return Sum(maxDecimal, smallest, Decimal{units: -1}) }},
+			{name: "max × 2", op: func() (Decimal, error) { return maxDecimal.Mul(NewFromInt(2), Scale, RoundHalfUp) }},
+			{name: "min × 3 / 2", op: func() (Decimal, error) { return minDecimal.MulRatio(3, 2, Scale, RoundHalfUp) }},
+			{name: "max / 0.5", op: func() (Decimal, error) { return maxDecimal.Div(MustParse("0.5"), Scale, RoundHalfUp) }},
+			{name: "max rounded up to cents", op: func() (Decimal, error) { return maxDecimal.Round(CentPlaces, RoundUp) }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := tt.op()
+
+				assert.ErrorIs(t, err, ErrOverflow)
+			})
+		}
+	})
+
+	t.Run("should keep results at the bounds", func(t *testing.T) {
+		product, err := maxDecimal.Mul(NewFromInt(1), Scale, RoundHalfUp)
+		assert.NoError(t, err)
+		assert.Equal(t, maxDecimal, product)
+
+		ratio, err := minDecimal.MulRatio(2, 2, Scale, RoundHalfUp)
+		assert.NoError(t, err)
+		assert.Equal(t, minDecimal, ratio)
+
+		negated, err := Decimal{units: -math.MaxInt64}.Neg()
+		assert.NoError(t, err)
+		assert.Equal(t, maxDecimal, negated)
+	})
+
+	t.Run("should format a bound that can't be rounded in range", func(t *testing.T) {
+		assert.Equal(t, "92233720368.55", maxDecimal.StringFixed(CentPlaces))
+		assert.Equal(t, "-92233720368.55", minDecimal.StringFixed(CentPlaces))
+	})
+
+	t.Run("should refuse to scan a column value out of range", func(t *testing.T) {
+		var d Decimal
+
+		assert.ErrorIs(t, d.Scan(int64(math.MaxInt64)), ErrOverflow)
+		assert.Error(t, d.Scan("92233720369"))
+	})
+}
+
+func TestJSON(t *testing.T) {
+	var d struct {
+		Number Decimal `json:"number"`
+		String Decimal `json:"string"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"number": 12.5, "string": "0.10"}`), &d))
+	assert.Equal(t, MustParse("12.5"), d.Number)
+	assert.Equal(t, MustParse("0.1"), d.String)
+
+	data, err := json.Marshal(d)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"number": 12.5, "string": 0.1}`, string(data))
+}
+
+func TestScan(t *testing.T) {
+	var d Decimal
+
+	assert.NoError(t, d.Scan([]byte("99999999.99")))
+	assert.Equal(t, "99999999.99", d.String())
+
+	value, err := d.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "99999999.99", value)
+}
@@ -0,0 +1,99 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mercor/payment-service/pkg/apperror"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+var ErrInvalidCursor = apperror.Validation("invalid cursor")
+
+// Request asks for the page of at most Limit records that follows the opaque Cursor.
+// An empty cursor asks for the first page.
+type Request struct {
+	Limit  int
+	Cursor string
+}
+
+// Page is a page of records, NextCursor is empty on the last page
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type cursor struct {
+	After string `json:"after"`
+}
+
+// EncodeCursor returns the opaque cursor of the page that starts after the given key
+func EncodeCursor(after string) string {
+	raw, _ := json.Marshal(cursor{After: after})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor returns the key an opaque cursor points after
+func DecodeCursor(encoded string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.After == "" {
+		return "", ErrInvalidCursor
+	}
+
+	return c.After, nil
+}
+
+// GetLimit returns the requested limit bounded to [1, MaxLimit], DefaultLimit when unset
+func (r Request) GetLimit() int {
+	switch {
+	case r.Limit <= 0:
+		return DefaultLimit
+	case r.Limit > MaxLimit:
+		return MaxLimit
+	default:
+		return r.Limit
+	}
+}
+
+// Apply restricts the query to the requested page using keyset pagination on column, which must be
+// unique within the result set. One extra row is fetched to know whether a next page exists.
+func Apply(db *gorm.DB, column string, req Request) (*gorm.DB, error) {
+	if req.Cursor != "" {
+		after, err := DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(fmt.Sprintf("%s > ?", column), after)
+	}
+
+	return db.Order(fmt.Sprintf("%s ASC", column)).Limit(req.GetLimit() + 1), nil
+}
+
+// NewPage builds the page from the rows fetched by a query scoped with Apply, key returns the
+// value of the keyset column of a record
+func NewPage[T any](items []T, req Request, key func(T) string) *Page[T] {
+	limit := req.GetLimit()
+	if len(items) <= limit {
+		if items == nil {
+			items = make([]T, 0)
+		}
+		return &Page[T]{Data: items}
+	}
+
+	items = items[:limit]
+	return &Page[T]{
+		Data:       items,
+		NextCursor: EncodeCursor(key(items[limit-1])),
+	}
+}
@@ -0,0 +1,55 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("should decode the key an encoded cursor points after", func(t *testing.T) {
+		after, err := DecodeCursor(EncodeCursor("job_123"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "job_123", after)
+	})
+
+	t.Run("should reject a cursor that was not produced by EncodeCursor", func(t *testing.T) {
+		_, err := DecodeCursor("not-a-cursor")
+
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestRequestGetLimit(t *testing.T) {
+	assert.Equal(t, DefaultLimit, Request{}.GetLimit())
+	assert.Equal(t, 10, Request{Limit: 10}.GetLimit())
+	assert.Equal(t, MaxLimit, Request{Limit: MaxLimit + 1}.GetLimit())
+}
+
+func TestNewPage(t *testing.T) {
+	key := func(id string) string { return id }
+
+	t.Run("should not return a next cursor on the last page", func(t *testing.T) {
+		page := NewPage([]string{"a", "b"}, Request{Limit: 2}, key)
+
+		assert.Equal(t, []string{"a", "b"}, page.Data)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("should trim the extra row and point the next cursor at the last returned row", func(t *testing.T) {
+		page := NewPage([]string{"a", "b", "c"}, Request{Limit: 2}, key)
+
+		assert.Equal(t, []string{"a", "b"}, page.Data)
+		after, err := DecodeCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", after)
+	})
+
+	t.Run("should return an empty list rather than null when nothing matches", func(t *testing.T) {
+		page := NewPage[string](nil, Request{}, key)
+
+		assert.NotNil(t, page.Data)
+		assert.Empty(t, page.Data)
+	})
+}
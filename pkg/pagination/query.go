@@ -0,0 +1,34 @@
+package pagination
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/pkg/apperror"
+)
+
+const (
+	QueryLimit  = "limit"
+	QueryCursor = "cursor"
+)
+
+// FromQuery reads the limit and cursor query params of a list request, a missing limit pages by DefaultLimit
+func FromQuery(c *gin.Context) (Request, error) {
+	req := Request{Cursor: c.Query(QueryCursor)}
+
+	if rawLimit := c.Query(QueryLimit); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			return Request{}, apperror.Validation("limit must be a positive integer")
+		}
+		req.Limit = limit
+	}
+
+	if req.Cursor != "" {
+		if _, err := DecodeCursor(req.Cursor); err != nil {
+			return Request{}, err
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,45 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mercor/payment-service/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromQuery(t *testing.T) {
+	cursor := EncodeCursor("job_123")
+
+	tests := []struct {
+		name      string
+		query     string
+		want      Request
+		wantLimit int
+		wantErr   bool
+	}{
+		{name: "should page by the default limit without a limit", query: "", want: Request{}, wantLimit: DefaultLimit},
+		{name: "should read the limit and cursor", query: "?limit=10&cursor=" + cursor, want: Request{Limit: 10, Cursor: cursor}, wantLimit: 10},
+		{name: "should reject a limit that is not a number", query: "?limit=ten", wantErr: true},
+		{name: "should reject a limit that is not positive", query: "?limit=0", wantErr: true},
+		{name: "should reject a cursor that was not produced by EncodeCursor", query: "?cursor=not-a-cursor", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/jobs"+tt.query, nil)
+
+			req, err := FromQuery(c)
+
+			if tt.wantErr {
+				assert.True(t, apperror.HasCode(err, apperror.CodeValidation), err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, req)
+			assert.Equal(t, tt.wantLimit, req.GetLimit())
+		})
+	}
+}
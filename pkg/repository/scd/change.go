@@ -0,0 +1,35 @@
+package scd
+
+import (
+	"context"
+
+	"github.com/mercor/payment-service/constants"
+)
+
+// ChangeInfo describes who writes a version and why
+type ChangeInfo struct {
+	ChangedBy string
+	Reason    string
+}
+
+// WithChangeInfo returns a context whose writes stamp the new versions with the given author and reason
+func WithChangeInfo(ctx context.Context, changedBy, reason string) context.Context {
+	return context.WithValue(ctx, constants.SCDChangeInfo, &ChangeInfo{ChangedBy: changedBy, Reason: reason})
+}
+
+func setChangeInfo[T SCDRecord](ctx context.Context, record *T) {
+	info, ok := ctx.Value(constants.SCDChangeInfo).(*ChangeInfo)
+	if !ok {
+		(*record).SetChangeInfo(nil, nil)
+		return
+	}
+
+	(*record).SetChangeInfo(nullableString(info.ChangedBy), nullableString(info.Reason))
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
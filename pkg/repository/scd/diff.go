@@ -0,0 +1,99 @@
+package scd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// FieldChange is the value of a column before and after a version was written
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// VersionHistory describes a version of a record and the columns it changed compared to the previous version
+type VersionHistory struct {
+	UID          string        `json:"uid"`
+	Version      int           `json:"version"`
+	IsDeleted    bool          `json:"is_deleted"`
+	ValidFrom    time.Time     `json:"valid_from"`
+	ValidTo      *time.Time    `json:"valid_to"`
+	ChangedBy    *string       `json:"changed_by"`
+	ChangeReason *string       `json:"change_reason"`
+	Changes      []FieldChange `json:"changes"`
+}
+
+// DiffVersions computes the field level changes between consecutive versions of a record.
+// versions must belong to the same record and be ordered by version, as returned by FindVersionsForID.
+// The first version is reported with every non-empty column as a change from nil, columns are named by the naming
+// strategy of db like the repository names them.
+func DiffVersions[T SCDRecord](ctx context.Context, db *gorm.DB, versions []T) ([]VersionHistory, error) {
+	var model T
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&model); err != nil {
+		return nil, fmt.Errorf("failed to parse model type: %w", err)
+	}
+
+	return diffVersions(ctx, stmt.Schema, versions), nil
+}
+
+func diffVersions[T SCDRecord](ctx context.Context, sch *schema.Schema, versions []T) []VersionHistory {
+	history := make([]VersionHistory, 0, len(versions))
+
+	for i := range versions {
+		current := reflect.ValueOf(&versions[i]).Elem()
+		entry := VersionHistory{
+			UID:       versions[i].GetUID(),
+			Version:   versions[i].GetVersion(),
+			IsDeleted: versions[i].GetIsDeleted(),
+			Changes:   make([]FieldChange, 0),
+		}
+
+		for _, field := range sch.Fields {
+			if field.DBName == "" {
+				continue
+			}
+
+			newValue, newIsZero := field.ValueOf(ctx, current)
+			switch field.DBName {
+			case "valid_from":
+				entry.ValidFrom, _ = newValue.(time.Time)
+				continue
+			case "valid_to":
+				entry.ValidTo, _ = newValue.(*time.Time)
+				continue
+			case "changed_by":
+				entry.ChangedBy, _ = newValue.(*string)
+				continue
+			case "change_reason":
+				entry.ChangeReason, _ = newValue.(*string)
+				continue
+			}
+			if scdColumns[field.DBName] {
+				continue
+			}
+
+			if i == 0 {
+				if !newIsZero {
+					entry.Changes = append(entry.Changes, FieldChange{Field: field.DBName, New: newValue})
+				}
+				continue
+			}
+
+			oldValue, _ := field.ValueOf(ctx, reflect.ValueOf(&versions[i-1]).Elem())
+			if !reflect.DeepEqual(oldValue, newValue) {
+				entry.Changes = append(entry.Changes, FieldChange{Field: field.DBName, Old: oldValue, New: newValue})
+			}
+		}
+
+		history = append(history, entry)
+	}
+
+	return history
+}
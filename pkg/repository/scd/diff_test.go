@@ -0,0 +1,90 @@
+package scd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils/tests"
+)
+
+type diffTestRecord struct {
+	*SCDModel
+	Rate   float64 `gorm:"column:rate"`
+	Status string  `gorm:"column:status"`
+	Title  string
+}
+
+func newDiffTestRecord(version int, rate float64, status string) diffTestRecord {
+	return diffTestRecord{
+		SCDModel: &SCDModel{ID: "job_1", Version: version, UID: "uid_" + status},
+		Rate:     rate,
+		Status:   status,
+	}
+}
+
+func openDiffTestDB(t *testing.T, namer schema.Namer) *gorm.DB {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{NamingStrategy: namer})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestDiffVersions(t *testing.T) {
+	db := openDiffTestDB(t, schema.NamingStrategy{})
+
+	t.Run("should report every column of the first version as created", func(t *testing.T) {
+		history, err := DiffVersions(context.Background(), db, []diffTestRecord{
+			newDiffTestRecord(1, 20, "extended"),
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, history, 1)
+		assert.Equal(t, 1, history[0].Version)
+		assert.ElementsMatch(t, []FieldChange{
+			{Field: "rate", New: float64(20)},
+			{Field: "status", New: "extended"},
+		}, history[0].Changes)
+	})
+
+	t.Run("should only report columns that changed between consecutive versions", func(t *testing.T) {
+		history, err := DiffVersions(context.Background(), db, []diffTestRecord{
+			newDiffTestRecord(1, 20, "extended"),
+			newDiffTestRecord(2, 20, "active"),
+			newDiffTestRecord(3, 15.5, "active"),
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, history, 3)
+		assert.Equal(t, []FieldChange{{Field: "status", Old: "extended", New: "active"}}, history[1].Changes)
+		assert.Equal(t, []FieldChange{{Field: "rate", Old: float64(20), New: 15.5}}, history[2].Changes)
+	})
+
+	t.Run("should flag tombstone versions without reporting column changes", func(t *testing.T) {
+		tombstone := newDiffTestRecord(2, 20, "active")
+		tombstone.IsDeleted = true
+
+		history, err := DiffVersions(context.Background(), db, []diffTestRecord{
+			newDiffTestRecord(1, 20, "active"),
+			tombstone,
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, history[1].IsDeleted)
+		assert.Empty(t, history[1].Changes)
+	})
+
+	t.Run("should name the columns with the naming strategy of the database", func(t *testing.T) {
+		renamed := newDiffTestRecord(2, 20, "active")
+		renamed.Title = "Lead engineer"
+
+		history, err := DiffVersions(context.Background(), openDiffTestDB(t, schema.NamingStrategy{NoLowerCase: true}), []diffTestRecord{
+			newDiffTestRecord(1, 20, "active"),
+			renamed,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []FieldChange{{Field: "Title", Old: "", New: "Lead engineer"}}, history[1].Changes)
+	})
+}
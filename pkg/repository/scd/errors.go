@@ -0,0 +1,17 @@
+package scd
+
+import "github.com/mercor/payment-service/pkg/apperror"
+
+// ErrRecordNotFound is returned when no version of a record matches the lookup, or when a write
+// targets an ID that has no latest version
+var ErrRecordNotFound = apperror.NotFound("record not found")
+
+// ErrVersionConflict is returned when a record was changed by someone else between reading
+// its latest version and writing a new one
+var ErrVersionConflict = apperror.Conflict("version conflict: record was modified concurrently")
+
+// ErrInvalidChange is returned when a patch references a column that does not exist or cannot be changed
+var ErrInvalidChange = apperror.Validation("invalid change")
+
+// ErrNilRecord is returned for nil entries of a batch
+var ErrNilRecord = apperror.Validation("record cannot be nil")
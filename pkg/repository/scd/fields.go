@@ -0,0 +1,75 @@
+package scd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// scdColumns are maintained by the repository and can never be changed by callers
+var scdColumns = map[string]bool{
+	"id":         true,
+	"uid":        true,
+	"version":    true,
+	"is_latest":  true,
+	"is_deleted": true,
+	"valid_from": true,
+	"valid_to":   true,
+
+	"changed_by":    true,
+	"change_reason": true,
+}
+
+// parseSchema returns the GORM schema of the repository's model
+func (r *scdRepositoryImpl[T]) parseSchema(db *gorm.DB) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(r.modelType); err != nil {
+		return nil, fmt.Errorf("failed to parse model type: %w", err)
+	}
+	return stmt.Schema, nil
+}
+
+// cloneRecord returns a deep copy of every persisted column of src, embedded SCD model included
+func cloneRecord[T SCDRecord](ctx context.Context, sch *schema.Schema, src *T) (*T, error) {
+	var dst T
+	srcValue := reflect.ValueOf(src).Elem()
+	dstValue := reflect.ValueOf(&dst).Elem()
+
+	for _, field := range sch.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, isZero := field.ValueOf(ctx, srcValue)
+		if isZero {
+			continue
+		}
+		if err := field.Set(ctx, dstValue, value); err != nil {
+			return nil, fmt.Errorf("failed to copy column %s: %w", field.DBName, err)
+		}
+	}
+
+	return &dst, nil
+}
+
+// applyChanges sets the given columns on record, keys can be column names or struct field names
+func applyChanges[T SCDRecord](ctx context.Context, sch *schema.Schema, record *T, changes map[string]interface{}) error {
+	recordValue := reflect.ValueOf(record).Elem()
+
+	for key, value := range changes {
+		field := sch.LookUpField(key)
+		if field == nil || field.DBName == "" {
+			return fmt.Errorf("%w: unknown column %q", ErrInvalidChange, key)
+		}
+		if scdColumns[field.DBName] {
+			return fmt.Errorf("%w: column %q is managed by the repository", ErrInvalidChange, field.DBName)
+		}
+		if err := field.Set(ctx, recordValue, value); err != nil {
+			return fmt.Errorf("%w: column %q: %v", ErrInvalidChange, field.DBName, err)
+		}
+	}
+
+	return nil
+}
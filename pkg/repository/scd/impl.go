@@ -4,12 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// batchSize is the number of rows inserted per statement by the batch operations
+const batchSize = 500
+
 // scdRepositoryImpl is the implementation of SCDRepository
 type scdRepositoryImpl[T SCDRecord] struct {
 	db        *postgres.DbCluster
@@ -27,12 +33,12 @@ func NewSCDRepository[T SCDRecord](db *postgres.DbCluster, modelType T) SCDRepos
 func (r *scdRepositoryImpl[T]) FindByID(ctx context.Context, id string) (*T, error) {
 	var result T
 	err := r.db.GetSlaveDB(ctx).
-		Where("id = ? AND is_latest = ?", id, true).
+		Where("id = ? AND is_latest = ? AND is_deleted = ?", id, true, false).
 		First(&result).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
+			return nil, ErrRecordNotFound
 		}
 		return nil, fmt.Errorf("failed to find record by ID: %w", err)
 	}
@@ -49,7 +55,7 @@ func (r *scdRepositoryImpl[T]) FindByUID(ctx context.Context, uid string) (*T, e
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil
+			return nil, ErrRecordNotFound
 		}
 		return nil, fmt.Errorf("failed to find record by UID: %w", err)
 	}
@@ -57,11 +63,11 @@ func (r *scdRepositoryImpl[T]) FindByUID(ctx context.Context, uid string) (*T, e
 	return &result, nil
 }
 
-// FindAllLatest returns all latest versions of records
+// FindAllLatest returns all latest versions of records that are not deleted
 func (r *scdRepositoryImpl[T]) FindAllLatest(ctx context.Context) ([]T, error) {
 	var results []T
 	err := r.db.GetSlaveDB(ctx).
-		Where("is_latest = ?", true).
+		Where("is_latest = ? AND is_deleted = ?", true, false).
 		Find(&results).Error
 
 	if err != nil {
@@ -71,11 +77,11 @@ func (r *scdRepositoryImpl[T]) FindAllLatest(ctx context.Context) ([]T, error) {
 	return results, nil
 }
 
-// FindLatestWithFilter returns latest versions that match the filter
+// FindLatestWithFilter returns latest versions that match the filter and are not deleted
 func (r *scdRepositoryImpl[T]) FindLatestWithFilter(ctx context.Context, filter map[string]interface{}) ([]T, error) {
 	var results []T
 	err := r.db.GetSlaveDB(ctx).
-		Where("is_latest = ?", true).
+		Where("is_latest = ? AND is_deleted = ?", true, false).
 		Where(filter).
 		Find(&results).Error
 
@@ -86,7 +92,26 @@ func (r *scdRepositoryImpl[T]) FindLatestWithFilter(ctx context.Context, filter
 	return results, nil
 }
 
-// FindVersionsForID returns all versions of a record by ID
+// FindAllLatestPage returns a page of the latest versions of records that are not deleted, ordered by ID
+func (r *scdRepositoryImpl[T]) FindAllLatestPage(ctx context.Context, page pagination.Request) (*pagination.Page[T], error) {
+	query := r.db.GetSlaveDB(ctx).
+		Model(r.modelType).
+		Where("is_latest = ? AND is_deleted = ?", true, false)
+
+	return r.findPage(query, "id", page)
+}
+
+// FindLatestWithFilterPage returns a page of the latest versions that match the filter and are not deleted, ordered by ID
+func (r *scdRepositoryImpl[T]) FindLatestWithFilterPage(ctx context.Context, filter map[string]interface{}, page pagination.Request) (*pagination.Page[T], error) {
+	query := r.db.GetSlaveDB(ctx).
+		Model(r.modelType).
+		Where("is_latest = ? AND is_deleted = ?", true, false).
+		Where(filter)
+
+	return r.findPage(query, "id", page)
+}
+
+// FindVersionsForID returns all versions of a record by ID, including a tombstone if it was deleted
 func (r *scdRepositoryImpl[T]) FindVersionsForID(ctx context.Context, id string) ([]T, error) {
 	var results []T
 	err := r.db.GetSlaveDB(ctx).
@@ -101,12 +126,68 @@ func (r *scdRepositoryImpl[T]) FindVersionsForID(ctx context.Context, id string)
 	return results, nil
 }
 
+// FindHistoryForID returns every version of a record by ID with the columns each version changed
+func (r *scdRepositoryImpl[T]) FindHistoryForID(ctx context.Context, id string) ([]VersionHistory, error) {
+	versions, err := r.FindVersionsForID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	sch, err := r.parseSchema(r.db.GetSlaveDB(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return diffVersions(ctx, sch, versions), nil
+}
+
+// FindByIDAsOf returns the version of a record by ID that was current at asOf
+func (r *scdRepositoryImpl[T]) FindByIDAsOf(ctx context.Context, id string, asOf time.Time) (*T, error) {
+	var result T
+	err := r.db.GetSlaveDB(ctx).
+		Where("id = ? AND is_deleted = ?", id, false).
+		Where("valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", asOf, asOf).
+		First(&result).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to find record by ID as of %s: %w", asOf, err)
+	}
+
+	return &result, nil
+}
+
+// FindLatestWithFilterAsOf returns versions that were current at asOf and match the filter
+func (r *scdRepositoryImpl[T]) FindLatestWithFilterAsOf(ctx context.Context, filter map[string]interface{}, asOf time.Time) ([]T, error) {
+	var results []T
+	err := r.db.GetSlaveDB(ctx).
+		Where("valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", asOf, asOf).
+		Where("is_deleted = ?", false).
+		Where(filter).
+		Find(&results).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find records with filter as of %s: %w", asOf, err)
+	}
+
+	return results, nil
+}
+
 // Create creates a new record with version 1
 func (r *scdRepositoryImpl[T]) Create(ctx context.Context, record *T) error {
 	(*record).SetVersion(1)
 	(*record).SetID(uuid.New().String())
 	(*record).SetIsLatest(true)
+	(*record).SetIsDeleted(false)
 	(*record).SetUID(uuid.New().String())
+	(*record).SetValidFrom(time.Now().UTC())
+	(*record).SetValidTo(nil)
+	setChangeInfo(ctx, record)
 
 	err := r.db.GetMasterDB(ctx).Create(record).Error
 	if err != nil {
@@ -116,75 +197,426 @@ func (r *scdRepositoryImpl[T]) Create(ctx context.Context, record *T) error {
 	return nil
 }
 
+// CreateMany creates every record with version 1 in a single transaction.
+// The returned slice holds the error of each record at the same index, nil records are reported and skipped.
+// A non-nil error means the transaction failed and nothing was written.
+func (r *scdRepositoryImpl[T]) CreateMany(ctx context.Context, records []*T) ([]error, error) {
+	recordErrs := make([]error, len(records))
+	valid := make([]*T, 0, len(records))
+	now := time.Now().UTC()
+
+	for i, record := range records {
+		if record == nil {
+			recordErrs[i] = ErrNilRecord
+			continue
+		}
+
+		(*record).SetVersion(1)
+		(*record).SetID(uuid.New().String())
+		(*record).SetIsLatest(true)
+		(*record).SetIsDeleted(false)
+		(*record).SetUID(uuid.New().String())
+		(*record).SetValidFrom(now)
+		(*record).SetValidTo(nil)
+		setChangeInfo(ctx, record)
+		valid = append(valid, record)
+	}
+
+	if len(valid) == 0 {
+		return recordErrs, nil
+	}
+
+	err := r.db.GetMasterDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(valid, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to create records: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recordErrs, nil
+}
+
+// UpdateMany creates a new version of every record, identified by its ID, in a single transaction.
+// The previous versions are locked and their is_latest flag is switched off with one statement.
+// The returned slice holds the error of each record at the same index, records that are nil, unknown,
+// deleted or repeated within the batch are reported and skipped. A non-nil error means the transaction
+// failed and nothing was written.
+func (r *scdRepositoryImpl[T]) UpdateMany(ctx context.Context, records []*T) ([]error, error) {
+	recordErrs := make([]error, len(records))
+	indexByID := make(map[string]int, len(records))
+	ids := make([]string, 0, len(records))
+
+	for i, record := range records {
+		if record == nil {
+			recordErrs[i] = ErrNilRecord
+			continue
+		}
+
+		id := (*record).GetID()
+		if _, duplicate := indexByID[id]; duplicate {
+			recordErrs[i] = fmt.Errorf("%w: id %s appears more than once in the batch", ErrInvalidChange, id)
+			continue
+		}
+		indexByID[id] = i
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return recordErrs, nil
+	}
+
+	err := r.db.GetMasterDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var latestRecords []T
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND is_latest = ?", ids, true).
+			Find(&latestRecords).Error
+		if err != nil {
+			return fmt.Errorf("failed to find latest versions: %w", err)
+		}
+
+		latestVersions := make(map[string]int, len(latestRecords))
+		for _, latest := range latestRecords {
+			if !latest.GetIsDeleted() {
+				latestVersions[latest.GetID()] = latest.GetVersion()
+			}
+		}
+
+		now := time.Now().UTC()
+		toFlip := make([]string, 0, len(ids))
+		toCreate := make([]*T, 0, len(ids))
+		for _, id := range ids {
+			i := indexByID[id]
+			latestVersion, found := latestVersions[id]
+			if !found {
+				recordErrs[i] = fmt.Errorf("%w: id %s", ErrRecordNotFound, id)
+				continue
+			}
+
+			record := records[i]
+			(*record).SetVersion(latestVersion + 1)
+			(*record).SetIsLatest(true)
+			(*record).SetIsDeleted(false)
+			(*record).SetUID(uuid.New().String())
+			(*record).SetValidFrom(now)
+			(*record).SetValidTo(nil)
+			setChangeInfo(ctx, record)
+
+			toFlip = append(toFlip, id)
+			toCreate = append(toCreate, record)
+		}
+
+		if len(toCreate) == 0 {
+			return nil
+		}
+
+		res := tx.Model(r.modelType).
+			Where("id IN ? AND is_latest = ?", toFlip, true).
+			Updates(map[string]interface{}{"is_latest": false, "valid_to": now})
+		if res.Error != nil {
+			return fmt.Errorf("failed to update latest flags: %w", res.Error)
+		}
+		if res.RowsAffected != int64(len(toFlip)) {
+			return ErrVersionConflict
+		}
+
+		if err := tx.CreateInBatches(toCreate, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to update records: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recordErrs, nil
+}
+
 // Update creates a new version of an existing record
 func (r *scdRepositoryImpl[T]) Update(ctx context.Context, id string, record *T) error {
-	// Find the latest version
-	latestRecord, err := r.FindByID(ctx, id)
+	return r.appendVersion(ctx, id, nil, func(*gorm.DB, *T) (*T, error) {
+		return record, nil
+	})
+}
+
+// UpdateIfVersion creates a new version of an existing record only if its latest version is still expectedVersion
+func (r *scdRepositoryImpl[T]) UpdateIfVersion(ctx context.Context, id string, expectedVersion int, record *T) error {
+	return r.appendVersion(ctx, id, &expectedVersion, func(*gorm.DB, *T) (*T, error) {
+		return record, nil
+	})
+}
+
+// Patch creates a new version of an existing record from its latest version with only the given columns changed
+func (r *scdRepositoryImpl[T]) Patch(ctx context.Context, id string, changes map[string]interface{}) (*T, error) {
+	return r.patch(ctx, id, nil, changes)
+}
+
+// PatchIfVersion is Patch guarded by the version the caller last read
+func (r *scdRepositoryImpl[T]) PatchIfVersion(ctx context.Context, id string, expectedVersion int, changes map[string]interface{}) (*T, error) {
+	return r.patch(ctx, id, &expectedVersion, changes)
+}
+
+func (r *scdRepositoryImpl[T]) patch(ctx context.Context, id string, expectedVersion *int, changes map[string]interface{}) (*T, error) {
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: no columns to change", ErrInvalidChange)
+	}
+
+	var patched *T
+	err := r.appendVersion(ctx, id, expectedVersion, func(tx *gorm.DB, latest *T) (*T, error) {
+		sch, err := r.parseSchema(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		patched, err = cloneRecord(ctx, sch, latest)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := applyChanges(ctx, sch, patched, changes); err != nil {
+			return nil, err
+		}
+
+		return patched, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to find latest version: %w", err)
+		return nil, err
 	}
 
-	if latestRecord == nil {
-		return errors.New("record not found")
+	return patched, nil
+}
+
+// Delete appends a tombstone version that copies the latest version of the record
+func (r *scdRepositoryImpl[T]) Delete(ctx context.Context, id string) error {
+	return r.appendVersion(ctx, id, nil, func(tx *gorm.DB, latest *T) (*T, error) {
+		sch, err := r.parseSchema(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		tombstone, err := cloneRecord(ctx, sch, latest)
+		if err != nil {
+			return nil, err
+		}
+		(*tombstone).SetIsDeleted(true)
+
+		return tombstone, nil
+	})
+}
+
+// RevertToVersion creates a new latest version that copies the columns of an earlier version of the record.
+// History is never rewritten, the reverted version stays in place and the revert shows up as a new version.
+func (r *scdRepositoryImpl[T]) RevertToVersion(ctx context.Context, id string, version int) (*T, error) {
+	return r.revertToVersion(ctx, id, version, nil)
+}
+
+// RevertToVersionIfVersion is RevertToVersion guarded by the version the caller last read
+func (r *scdRepositoryImpl[T]) RevertToVersionIfVersion(ctx context.Context, id string, version int, expectedVersion int) (*T, error) {
+	return r.revertToVersion(ctx, id, version, &expectedVersion)
+}
+
+func (r *scdRepositoryImpl[T]) revertToVersion(ctx context.Context, id string, version int, expectedVersion *int) (*T, error) {
+	var reverted *T
+	err := r.appendVersion(ctx, id, expectedVersion, func(tx *gorm.DB, latest *T) (*T, error) {
+		if (*latest).GetVersion() == version {
+			return nil, fmt.Errorf("%w: version %d is already the latest version", ErrInvalidChange, version)
+		}
+
+		var target T
+		err := tx.Where("id = ? AND version = ?", id, version).First(&target).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: version %d", ErrRecordNotFound, version)
+			}
+			return nil, fmt.Errorf("failed to find version %d: %w", version, err)
+		}
+		if target.GetIsDeleted() {
+			return nil, fmt.Errorf("%w: version %d is a tombstone", ErrInvalidChange, version)
+		}
+
+		sch, err := r.parseSchema(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		reverted, err = cloneRecord(ctx, sch, &target)
+		if err != nil {
+			return nil, err
+		}
+
+		return reverted, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Set the new version
-	(*record).SetVersion((*latestRecord).GetVersion() + 1)
-	(*record).SetIsLatest(true)
-	(*record).SetUID(uuid.New().String())
-	(*record).SetID((*latestRecord).GetID())
+	return reverted, nil
+}
+
+// appendVersion writes the record returned by build as the version following the latest version of id.
+// The latest version is read on master inside the transaction, and the switch of the is_latest flag is
+// guarded by its version so that concurrent writers cannot both succeed.
+func (r *scdRepositoryImpl[T]) appendVersion(ctx context.Context, id string, expectedVersion *int, build func(tx *gorm.DB, latest *T) (*T, error)) error {
+	return r.db.GetMasterDB(ctx).Transaction(func(tx *gorm.DB) error {
+		// Find the latest version
+		var latestRecord T
+		err := tx.Where("id = ? AND is_latest = ?", id, true).First(&latestRecord).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRecordNotFound
+			}
+			return fmt.Errorf("failed to find latest version: %w", err)
+		}
+
+		if latestRecord.GetIsDeleted() {
+			return ErrRecordNotFound
+		}
+
+		latestVersion := latestRecord.GetVersion()
+		if expectedVersion != nil && *expectedVersion != latestVersion {
+			return ErrVersionConflict
+		}
+
+		record, err := build(tx, &latestRecord)
+		if err != nil {
+			return err
+		}
+
+		// Set the new version, the previous one stops being valid at the same instant
+		now := time.Now().UTC()
+		(*record).SetVersion(latestVersion + 1)
+		(*record).SetIsLatest(true)
+		(*record).SetUID(uuid.New().String())
+		(*record).SetID(latestRecord.GetID())
+		(*record).SetValidFrom(now)
+		(*record).SetValidTo(nil)
+		setChangeInfo(ctx, record)
+
+		// Update the latest flag and close the validity window of the old version. Another writer
+		// that got here first has already flipped the flag, in which case no row matches.
+		res := tx.Model(r.modelType).
+			Where("id = ? AND version = ? AND is_latest = ?", latestRecord.GetID(), latestVersion, true).
+			Updates(map[string]interface{}{"is_latest": false, "valid_to": now})
+		if res.Error != nil {
+			return fmt.Errorf("failed to update latest flag: %w", res.Error)
+		}
+		if res.RowsAffected != 1 {
+			return ErrVersionConflict
+		}
 
-	// Execute operations within a transaction
-	err = r.db.GetMasterDB(ctx).Transaction(func(tx *gorm.DB) error {
 		// Create a new record with incremented version
 		if err := tx.Create(record).Error; err != nil {
 			return fmt.Errorf("failed to update record: %w", err)
 		}
 
-		// Update the latest flag for the old version
-		if err := tx.Model(r.modelType).
-			Where("uid = ? AND is_latest = ?", (*latestRecord).GetUID(), true).
-			Update("is_latest", false).Error; err != nil {
-			return fmt.Errorf("failed to update latest flag: %w", err)
-		}
-
 		return nil
 	})
+}
+
+// CustomQuery executes a custom query with SCD handling
+func (r *scdRepositoryImpl[T]) CustomQuery(ctx context.Context, queryBuilder func(*gorm.DB) *gorm.DB) ([]T, error) {
+	query, _, err := r.customQuery(ctx, queryBuilder, latestScope)
+	if err != nil {
+		return nil, err
+	}
 
+	var results []T
+	err = query.Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute custom query: %w", err)
+	}
+
+	return results, nil
+}
+
+// CustomScan executes a custom query with SCD handling and scans the selected columns into dest
+func (r *scdRepositoryImpl[T]) CustomScan(ctx context.Context, queryBuilder func(*gorm.DB) *gorm.DB, dest interface{}) error {
+	query, _, err := r.customQuery(ctx, queryBuilder, latestScope)
 	if err != nil {
 		return err
 	}
 
+	if err := query.Scan(dest).Error; err != nil {
+		return fmt.Errorf("failed to scan custom query: %w", err)
+	}
 	return nil
 }
 
-// CustomQuery executes a custom query with SCD handling
-func (r *scdRepositoryImpl[T]) CustomQuery(ctx context.Context, queryBuilder func(*gorm.DB) *gorm.DB) ([]T, error) {
+// CustomQueryPage executes a custom query with SCD handling and returns the requested page ordered by ID
+func (r *scdRepositoryImpl[T]) CustomQueryPage(ctx context.Context, queryBuilder func(*gorm.DB) *gorm.DB, page pagination.Request) (*pagination.Page[T], error) {
+	query, tableName, err := r.customQuery(ctx, queryBuilder, latestScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.findPage(query, tableName+".id", page)
+}
+
+// CustomQueryAsOf executes a custom query against the versions that were current at asOf
+func (r *scdRepositoryImpl[T]) CustomQueryAsOf(ctx context.Context, asOf time.Time, queryBuilder func(*gorm.DB) *gorm.DB) ([]T, error) {
+	query, _, err := r.customQuery(ctx, queryBuilder, func(tableName string, db *gorm.DB) *gorm.DB {
+		return db.Where(
+			fmt.Sprintf("%[1]s.valid_from <= ? AND (%[1]s.valid_to IS NULL OR %[1]s.valid_to > ?) AND %[1]s.is_deleted = ?", tableName),
+			asOf, asOf, false,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	var results []T
+	err = query.Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute custom query as of %s: %w", asOf, err)
+	}
+
+	return results, nil
+}
+
+// latestScope restricts a custom query to the latest versions that are not deleted
+func latestScope(tableName string, db *gorm.DB) *gorm.DB {
+	// Use qualified column name to avoid ambiguity
+	return db.Where(fmt.Sprintf("%[1]s.is_latest = ? AND %[1]s.is_deleted = ?", tableName), true, false)
+}
 
+// customQuery builds a custom query on top of the versions selected by versionScope and returns it with the model's table name
+func (r *scdRepositoryImpl[T]) customQuery(
+	ctx context.Context,
+	queryBuilder func(*gorm.DB) *gorm.DB,
+	versionScope func(tableName string, db *gorm.DB) *gorm.DB,
+) (*gorm.DB, string, error) {
 	// Get master DB
 	db := r.db.GetMasterDB(ctx)
 
-	stmt := &gorm.Statement{DB: db}
-	err := stmt.Parse(r.modelType)
+	sch, err := r.parseSchema(db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse model type: %w", err)
+		return nil, "", err
 	}
-	tableName := stmt.Table
+	tableName := sch.Table
 
-	// Start with a base query that includes only the latest versions
-	// Use qualified column name to avoid ambiguity
-	baseQuery := db.Model(r.modelType).
-		Where(fmt.Sprintf("%s.is_latest = ?", tableName), true)
+	// Start with a base query that only includes the requested versions
+	baseQuery := versionScope(tableName, db.Model(r.modelType))
 
 	// Apply the user's custom query function to the base query
-	customQuery := queryBuilder(baseQuery)
+	return queryBuilder(baseQuery), tableName, nil
+}
 
-	// Execute the combined query
-	err = customQuery.Find(&results).Error
+// findPage executes the query for the requested page using keyset pagination on the given ID column
+func (r *scdRepositoryImpl[T]) findPage(query *gorm.DB, idColumn string, page pagination.Request) (*pagination.Page[T], error) {
+	query, err := pagination.Apply(query, idColumn, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute custom query: %w", err)
+		return nil, err
 	}
 
-	return results, nil
+	var results []T
+	if err := query.Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	return pagination.NewPage(results, page, func(record T) string {
+		return record.GetID()
+	}), nil
 }
@@ -0,0 +1,458 @@
+package scd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/db/sql/postgres/postgrestest"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// repoTestRecord is a record of the scd_record table that newTestRepository creates
+type repoTestRecord struct {
+	*SCDModel
+	Name string `gorm:"column:name;not null"`
+}
+
+func (repoTestRecord) TableName() string {
+	return "scd_record"
+}
+
+func newRepoTestRecord(name string) *repoTestRecord {
+	return &repoTestRecord{SCDModel: &SCDModel{}, Name: name}
+}
+
+// newTestRepository returns a repository on an empty scd_record table that has the columns and indexes
+// the migrations give every SCD table. The test is skipped without a test database.
+func newTestRepository(t *testing.T) (SCDRepository[repoTestRecord], *postgres.DbCluster) {
+	db := postgrestest.Open(t)
+	postgrestest.Exec(t, db,
+		`CREATE TABLE scd_record (
+			id VARCHAR(255) NOT NULL,
+			version INTEGER NOT NULL,
+			uid VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			is_latest BOOLEAN NOT NULL,
+			is_deleted BOOLEAN NOT NULL DEFAULT false,
+			valid_from TIMESTAMP NOT NULL DEFAULT now(),
+			valid_to TIMESTAMP,
+			changed_by VARCHAR(255),
+			change_reason TEXT,
+			PRIMARY KEY (id, version)
+		)`,
+		`CREATE UNIQUE INDEX scd_record_uid_uidx ON scd_record(uid)`,
+		`CREATE UNIQUE INDEX scd_record_id_latest_uidx ON scd_record(id) WHERE is_latest`,
+	)
+	return NewSCDRepository(db, repoTestRecord{}), db
+}
+
+// waitForLockWait waits until a session of the test database is blocked on a row lock
+func waitForLockWait(t *testing.T, db *postgres.DbCluster) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		var waiting int64
+		err := db.GetMasterDB(context.Background()).
+			Raw("SELECT count(*) FROM pg_stat_activity WHERE wait_event_type = 'Lock' AND datname = current_database()").
+			Scan(&waiting).Error
+		if err != nil {
+			t.Fatalf("failed to look for lock waits: %v", err)
+		}
+		if waiting > 0 {
+			return
+		}
+	}
+	t.Fatal("no session waited for a lock")
+}
+
+func TestAppendVersionConcurrentWriters(t *testing.T) {
+	repo, db := newTestRepository(t)
+	ctx := context.Background()
+
+	t.Run("should let exactly one of two writers at the same version win", func(t *testing.T) {
+		record := newRepoTestRecord("v1")
+		if !assert.NoError(t, repo.Create(ctx, record)) {
+			return
+		}
+
+		start := make(chan struct{})
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		for i := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				errs[i] = repo.UpdateIfVersion(ctx, record.GetID(), 1, newRepoTestRecord(fmt.Sprintf("writer %d", i)))
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		conflicts := 0
+		for _, err := range errs {
+			if errors.Is(err, ErrVersionConflict) {
+				conflicts++
+				continue
+			}
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, conflicts)
+
+		versions, err := repo.FindVersionsForID(ctx, record.GetID())
+		assert.NoError(t, err)
+		if assert.Len(t, versions, 2) {
+			assert.False(t, versions[0].IsLatest)
+			assert.True(t, versions[1].IsLatest)
+		}
+	})
+
+	t.Run("should fail the writer whose latest version was replaced while it waited for the row", func(t *testing.T) {
+		record := newRepoTestRecord("v1")
+		if !assert.NoError(t, repo.Create(ctx, record)) {
+			return
+		}
+
+		// The second writer reads version 1 as the latest before the first one commits version 2,
+		// so it passes the version check and only the guarded flip of is_latest can catch it
+		waiting := make(chan error, 1)
+		err := db.RunInTx(ctx, func(txCtx context.Context) error {
+			if err := repo.UpdateIfVersion(txCtx, record.GetID(), 1, newRepoTestRecord("first")); err != nil {
+				return err
+			}
+			go func() {
+				waiting <- repo.UpdateIfVersion(ctx, record.GetID(), 1, newRepoTestRecord("second"))
+			}()
+			waitForLockWait(t, db)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.ErrorIs(t, <-waiting, ErrVersionConflict)
+
+		latest, err := repo.FindByID(ctx, record.GetID())
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, latest.GetVersion())
+			assert.Equal(t, "first", latest.Name)
+		}
+	})
+
+	t.Run("should fail a writer that read a version that is no longer the latest", func(t *testing.T) {
+		record := newRepoTestRecord("v1")
+		if !assert.NoError(t, repo.Create(ctx, record)) {
+			return
+		}
+		assert.NoError(t, repo.UpdateIfVersion(ctx, record.GetID(), 1, newRepoTestRecord("v2")))
+
+		err := repo.UpdateIfVersion(ctx, record.GetID(), 1, newRepoTestRecord("stale"))
+
+		assert.ErrorIs(t, err, ErrVersionConflict)
+	})
+}
+
+func TestAsOfReads(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+
+	record := newRepoTestRecord("first")
+	other := newRepoTestRecord("other")
+	if !assert.NoError(t, repo.Create(ctx, record)) || !assert.NoError(t, repo.Create(ctx, other)) {
+		return
+	}
+	if _, err := repo.Patch(ctx, record.GetID(), map[string]interface{}{"name": "second"}); !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, repo.Delete(ctx, record.GetID())) {
+		return
+	}
+
+	// Read the windows back, the database keeps microseconds
+	versions, err := repo.FindVersionsForID(ctx, record.GetID())
+	if !assert.NoError(t, err) || !assert.Len(t, versions, 3) {
+		return
+	}
+	created, patched, deleted := versions[0].ValidFrom, versions[1].ValidFrom, versions[2].ValidFrom
+	if assert.NotNil(t, versions[0].ValidTo) && assert.NotNil(t, versions[1].ValidTo) {
+		assert.Equal(t, patched, *versions[0].ValidTo)
+		assert.Equal(t, deleted, *versions[1].ValidTo)
+	}
+	assert.Nil(t, versions[2].ValidTo)
+
+	tests := []struct {
+		name     string
+		asOf     time.Time
+		wantName string
+	}{
+		{name: "should find nothing before the record was created", asOf: created.Add(-time.Microsecond)},
+		{name: "should include the start of a window", asOf: created, wantName: "first"},
+		{name: "should find the version that was current between two writes", asOf: patched.Add(-time.Microsecond), wantName: "first"},
+		{name: "should exclude the end of a window", asOf: patched, wantName: "second"},
+		{name: "should find the last version before the delete", asOf: deleted.Add(-time.Microsecond), wantName: "second"},
+		{name: "should exclude the tombstone", asOf: deleted},
+		{name: "should exclude the tombstone from then on", asOf: deleted.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := repo.FindByIDAsOf(ctx, record.GetID(), tt.asOf)
+			if tt.wantName == "" {
+				assert.ErrorIs(t, err, ErrRecordNotFound)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantName, found.Name)
+			}
+
+			filtered, err := repo.FindLatestWithFilterAsOf(ctx, map[string]interface{}{"id": record.GetID()}, tt.asOf)
+			assert.NoError(t, err)
+			assertNames(t, tt.wantName, filtered)
+
+			queried, err := repo.CustomQueryAsOf(ctx, tt.asOf, func(db *gorm.DB) *gorm.DB {
+				return db.Where("scd_record.id = ?", record.GetID())
+			})
+			assert.NoError(t, err)
+			assertNames(t, tt.wantName, queried)
+		})
+	}
+
+	t.Run("should apply the filter to the versions current at the time", func(t *testing.T) {
+		byName, err := repo.FindLatestWithFilterAsOf(ctx, map[string]interface{}{"name": "first"}, patched)
+		assert.NoError(t, err)
+		assert.Empty(t, byName)
+
+		all, err := repo.CustomQueryAsOf(ctx, patched, func(db *gorm.DB) *gorm.DB {
+			return db.Order("scd_record.name")
+		})
+		assert.NoError(t, err)
+		assertNames(t, "other second", all)
+	})
+}
+
+// assertNames asserts the names of the records, separated by spaces
+func assertNames(t *testing.T, want string, records []repoTestRecord) {
+	t.Helper()
+
+	names := make([]string, len(records))
+	for i, record := range records {
+		names[i] = record.Name
+	}
+	assert.Equal(t, want, strings.Join(names, " "))
+}
+
+func TestDelete(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+
+	record := newRepoTestRecord("deleted")
+	kept := newRepoTestRecord("kept")
+	if !assert.NoError(t, repo.Create(ctx, record)) || !assert.NoError(t, repo.Create(ctx, kept)) {
+		return
+	}
+	beforeDelete, err := repo.FindByID(ctx, record.GetID())
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, repo.Delete(ctx, record.GetID())) {
+		return
+	}
+
+	t.Run("should hide the record from latest reads", func(t *testing.T) {
+		_, err := repo.FindByID(ctx, record.GetID())
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+
+		all, err := repo.FindAllLatest(ctx)
+		assert.NoError(t, err)
+		assertNames(t, "kept", all)
+
+		filtered, err := repo.FindLatestWithFilter(ctx, map[string]interface{}{"id": record.GetID()})
+		assert.NoError(t, err)
+		assert.Empty(t, filtered)
+
+		queried, err := repo.CustomQuery(ctx, func(db *gorm.DB) *gorm.DB {
+			return db.Where("scd_record.name = ?", "deleted")
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, queried)
+	})
+
+	t.Run("should hide the record from as-of reads after the delete", func(t *testing.T) {
+		_, err := repo.FindByIDAsOf(ctx, record.GetID(), time.Now().UTC())
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	})
+
+	t.Run("should keep the record visible to as-of reads before the delete", func(t *testing.T) {
+		found, err := repo.FindByIDAsOf(ctx, record.GetID(), beforeDelete.ValidFrom)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, beforeDelete.GetUID(), found.GetUID())
+		}
+	})
+
+	t.Run("should keep the history with the tombstone as the latest version", func(t *testing.T) {
+		versions, err := repo.FindVersionsForID(ctx, record.GetID())
+
+		assert.NoError(t, err)
+		if assert.Len(t, versions, 2) {
+			assert.False(t, versions[0].IsDeleted)
+			assert.True(t, versions[1].IsDeleted)
+			assert.True(t, versions[1].IsLatest)
+			assert.Equal(t, "deleted", versions[1].Name)
+		}
+	})
+
+	t.Run("should refuse to write to a tombstone", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			write func() error
+		}{
+			{name: "delete", write: func() error { return repo.Delete(ctx, record.GetID()) }},
+			{name: "update", write: func() error { return repo.Update(ctx, record.GetID(), newRepoTestRecord("revived")) }},
+			{name: "update if version", write: func() error { return repo.UpdateIfVersion(ctx, record.GetID(), 2, newRepoTestRecord("revived")) }},
+			{name: "patch", write: func() error {
+				_, err := repo.Patch(ctx, record.GetID(), map[string]interface{}{"name": "revived"})
+				return err
+			}},
+			{name: "revert", write: func() error {
+				_, err := repo.RevertToVersion(ctx, record.GetID(), 1)
+				return err
+			}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.ErrorIs(t, tt.write(), ErrRecordNotFound)
+			})
+		}
+
+		versions, err := repo.FindVersionsForID(ctx, record.GetID())
+		assert.NoError(t, err)
+		assert.Len(t, versions, 2)
+	})
+
+	t.Run("should not find a record to delete", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Delete(ctx, "missing"), ErrRecordNotFound)
+	})
+}
+
+func TestCreateMany(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+
+	t.Run("should report nil records and create the others", func(t *testing.T) {
+		records := []*repoTestRecord{newRepoTestRecord("a"), nil, newRepoTestRecord("b")}
+
+		recordErrs, err := repo.CreateMany(ctx, records)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []error{nil, ErrNilRecord, nil}, recordErrs)
+		for _, record := range []*repoTestRecord{records[0], records[2]} {
+			found, err := repo.FindByID(ctx, record.GetID())
+			if assert.NoError(t, err) {
+				assert.Equal(t, 1, found.GetVersion())
+				assert.Equal(t, record.Name, found.Name)
+			}
+		}
+	})
+
+	t.Run("should write nothing when the insert fails", func(t *testing.T) {
+		valid := newRepoTestRecord("valid")
+		tooLong := newRepoTestRecord(strings.Repeat("x", 256))
+
+		_, err := repo.CreateMany(ctx, []*repoTestRecord{valid, tooLong})
+
+		assert.Error(t, err)
+		_, err = repo.FindByID(ctx, valid.GetID())
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+	})
+}
+
+func TestUpdateMany(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+
+	create := func(name string) *repoTestRecord {
+		record := newRepoTestRecord(name)
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		return record
+	}
+	next := func(record *repoTestRecord, name string) *repoTestRecord {
+		update := newRepoTestRecord(name)
+		update.SetID(record.GetID())
+		return update
+	}
+
+	updated := create("updated")
+	untouched := create("untouched")
+	deleted := create("deleted")
+	if !assert.NoError(t, repo.Delete(ctx, deleted.GetID())) {
+		return
+	}
+	repeated := create("repeated")
+	missing := newRepoTestRecord("missing")
+	missing.SetID("missing")
+
+	recordErrs, err := repo.UpdateMany(ctx, []*repoTestRecord{
+		next(updated, "updated v2"),
+		nil,
+		next(missing, "missing v2"),
+		next(deleted, "deleted v3"),
+		next(repeated, "repeated v2"),
+		next(repeated, "repeated again"),
+	})
+
+	t.Run("should report the error of each failed record by index", func(t *testing.T) {
+		assert.NoError(t, err)
+		if assert.Len(t, recordErrs, 6) {
+			assert.NoError(t, recordErrs[0])
+			assert.ErrorIs(t, recordErrs[1], ErrNilRecord)
+			assert.ErrorIs(t, recordErrs[2], ErrRecordNotFound)
+			assert.ErrorIs(t, recordErrs[3], ErrRecordNotFound)
+			assert.NoError(t, recordErrs[4])
+			assert.ErrorIs(t, recordErrs[5], ErrInvalidChange)
+		}
+	})
+
+	t.Run("should write a new latest version of the records that succeeded", func(t *testing.T) {
+		for id, want := range map[string]string{updated.GetID(): "updated v2", repeated.GetID(): "repeated v2"} {
+			versions, err := repo.FindVersionsForID(ctx, id)
+			if !assert.NoError(t, err) || !assert.Len(t, versions, 2) {
+				continue
+			}
+			assert.False(t, versions[0].IsLatest)
+			if assert.NotNil(t, versions[0].ValidTo) {
+				assert.Equal(t, versions[1].ValidFrom, *versions[0].ValidTo)
+			}
+			assert.True(t, versions[1].IsLatest)
+			assert.Nil(t, versions[1].ValidTo)
+			assert.Equal(t, want, versions[1].Name)
+		}
+	})
+
+	t.Run("should flip the latest flag of the intended records only", func(t *testing.T) {
+		versions, err := repo.FindVersionsForID(ctx, untouched.GetID())
+		if assert.NoError(t, err) && assert.Len(t, versions, 1) {
+			assert.True(t, versions[0].IsLatest)
+			assert.Nil(t, versions[0].ValidTo)
+		}
+
+		versions, err = repo.FindVersionsForID(ctx, deleted.GetID())
+		if assert.NoError(t, err) && assert.Len(t, versions, 2) {
+			assert.True(t, versions[1].IsLatest)
+			assert.True(t, versions[1].IsDeleted)
+		}
+
+		var latest int64
+		err = repo.CustomScan(ctx, func(db *gorm.DB) *gorm.DB {
+			return db.Select("count(*)")
+		}, &latest)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), latest, "updated, untouched and repeated")
+	})
+}
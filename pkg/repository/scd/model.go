@@ -1,11 +1,19 @@
 package scd
 
+import "time"
+
 // SCDModel is the base model for all SCD tables
 type SCDModel struct {
-	ID       string `gorm:"column:id;primaryKey:false"`
-	Version  int    `gorm:"column:version;primaryKey:false"`
-	UID      string `gorm:"column:uid;primaryKey"`
-	IsLatest bool   `gorm:"column:is_latest;not null" json:"is_latest"`
+	ID        string     `gorm:"column:id;primaryKey:false"`
+	Version   int        `gorm:"column:version;primaryKey:false"`
+	UID       string     `gorm:"column:uid;primaryKey"`
+	IsLatest  bool       `gorm:"column:is_latest;not null" json:"is_latest"`
+	IsDeleted bool       `gorm:"column:is_deleted;not null;default:false" json:"is_deleted"`
+	ValidFrom time.Time  `gorm:"column:valid_from;not null" json:"valid_from"`
+	ValidTo   *time.Time `gorm:"column:valid_to" json:"valid_to"`
+	// ChangedBy and ChangeReason record who wrote the version and why, when the writer provided it
+	ChangedBy    *string `gorm:"column:changed_by" json:"changed_by"`
+	ChangeReason *string `gorm:"column:change_reason" json:"change_reason"`
 }
 
 func (m SCDModel) GetID() string {
@@ -24,6 +32,18 @@ func (m SCDModel) GetIsLatest() bool {
 	return m.IsLatest
 }
 
+func (m SCDModel) GetIsDeleted() bool {
+	return m.IsDeleted
+}
+
+func (m SCDModel) GetValidFrom() time.Time {
+	return m.ValidFrom
+}
+
+func (m SCDModel) GetValidTo() *time.Time {
+	return m.ValidTo
+}
+
 func (m *SCDModel) SetVersion(version int) {
 	m.Version = version
 }
@@ -39,3 +59,20 @@ func (m *SCDModel) SetID(id string) {
 func (m *SCDModel) SetIsLatest(isLatest bool) {
 	m.IsLatest = isLatest
 }
+
+func (m *SCDModel) SetIsDeleted(isDeleted bool) {
+	m.IsDeleted = isDeleted
+}
+
+func (m *SCDModel) SetValidFrom(validFrom time.Time) {
+	m.ValidFrom = validFrom
+}
+
+func (m *SCDModel) SetValidTo(validTo *time.Time) {
+	m.ValidTo = validTo
+}
+
+func (m *SCDModel) SetChangeInfo(changedBy, changeReason *string) {
+	m.ChangedBy = changedBy
+	m.ChangeReason = changeReason
+}
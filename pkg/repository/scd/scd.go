@@ -2,7 +2,9 @@ package scd
 
 import (
 	"context"
+	"time"
 
+	"github.com/mercor/payment-service/pkg/pagination"
 	"gorm.io/gorm"
 )
 
@@ -11,13 +13,19 @@ type SCDRecord interface {
 	GetID() string
 	GetVersion() int
 	GetUID() string
+	GetIsDeleted() bool
 	SetVersion(version int)
 	SetID(id string)
 	SetIsLatest(isLatest bool)
+	SetIsDeleted(isDeleted bool)
 	SetUID(id string)
+	SetValidFrom(validFrom time.Time)
+	SetValidTo(validTo *time.Time)
+	SetChangeInfo(changedBy, changeReason *string)
 }
 
 // SCDRepository is a generic repository for SCD tables
+// Lookups of a single record return ErrRecordNotFound when nothing matches.
 type SCDRepository[T SCDRecord] interface {
 	FindByID(ctx context.Context, id string) (*T, error)
 
@@ -25,13 +33,63 @@ type SCDRepository[T SCDRecord] interface {
 
 	FindAllLatest(ctx context.Context) ([]T, error)
 
+	FindAllLatestPage(ctx context.Context, page pagination.Request) (*pagination.Page[T], error)
+
 	FindLatestWithFilter(ctx context.Context, filter map[string]interface{}) ([]T, error)
 
+	FindLatestWithFilterPage(ctx context.Context, filter map[string]interface{}, page pagination.Request) (*pagination.Page[T], error)
+
 	FindVersionsForID(ctx context.Context, id string) ([]T, error)
 
+	// FindHistoryForID returns every version of a record with the columns it changed compared to the previous one
+	FindHistoryForID(ctx context.Context, id string) ([]VersionHistory, error)
+
+	// FindByIDAsOf returns the version of a record that was current at asOf
+	FindByIDAsOf(ctx context.Context, id string, asOf time.Time) (*T, error)
+
+	// FindLatestWithFilterAsOf returns the versions that were current at asOf and match the filter
+	FindLatestWithFilterAsOf(ctx context.Context, filter map[string]interface{}, asOf time.Time) ([]T, error)
+
 	Create(ctx context.Context, record *T) error
 
+	// CreateMany creates all records in one transaction and returns the error of each record by index
+	CreateMany(ctx context.Context, records []*T) ([]error, error)
+
 	Update(ctx context.Context, id string, record *T) error
 
+	// UpdateMany writes a new version of every record, identified by its ID, in one transaction
+	// and returns the error of each record by index
+	UpdateMany(ctx context.Context, records []*T) ([]error, error)
+
+	// UpdateIfVersion is Update guarded by the version the caller last read, it returns
+	// ErrVersionConflict when the latest version has moved on
+	UpdateIfVersion(ctx context.Context, id string, expectedVersion int, record *T) error
+
+	// Patch creates a new version that copies the latest one with only the given columns changed
+	Patch(ctx context.Context, id string, changes map[string]interface{}) (*T, error)
+
+	// PatchIfVersion is Patch guarded by the version the caller last read, it returns
+	// ErrVersionConflict when the latest version has moved on
+	PatchIfVersion(ctx context.Context, id string, expectedVersion int, changes map[string]interface{}) (*T, error)
+
+	// RevertToVersion creates a new latest version that copies the given earlier version
+	RevertToVersion(ctx context.Context, id string, version int) (*T, error)
+
+	// RevertToVersionIfVersion is RevertToVersion guarded by the version the caller last read, it returns
+	// ErrVersionConflict when the latest version has moved on
+	RevertToVersionIfVersion(ctx context.Context, id string, version int, expectedVersion int) (*T, error)
+
+	// Delete appends a tombstone version, latest-only queries skip the record afterwards
+	Delete(ctx context.Context, id string) error
+
 	CustomQuery(ctx context.Context, queryBuilder func(*gorm.DB) *gorm.DB) ([]T, error)
+
+	// CustomScan is CustomQuery scanning into dest, for selects such as aggregates that don't return T
+	CustomScan(ctx context.Context, queryBuilder func(*gorm.DB) *gorm.DB, dest interface{}) error
+
+	// CustomQueryPage is CustomQuery returning the requested page ordered by ID
+	CustomQueryPage(ctx context.Context, queryBuilder func(*gorm.DB) *gorm.DB, page pagination.Request) (*pagination.Page[T], error)
+
+	// CustomQueryAsOf is CustomQuery evaluated against the versions that were current at asOf
+	CustomQueryAsOf(ctx context.Context, asOf time.Time, queryBuilder func(*gorm.DB) *gorm.DB) ([]T, error)
 }
@@ -0,0 +1,9 @@
+package static
+
+import "github.com/mercor/payment-service/pkg/apperror"
+
+// ErrRecordNotFound is returned by GetByConditions when no record matches the filter
+var ErrRecordNotFound = apperror.NotFound("record not found")
+
+// ErrNilRecord is returned when a nil record is passed to a write
+var ErrNilRecord = apperror.Validation("record cannot be nil")
@@ -6,6 +6,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/mercor/payment-service/pkg/db/sql/postgres"
+	"github.com/mercor/payment-service/pkg/pagination"
+	"gorm.io/gorm"
 )
 
 type staticRepositoryImpl[T Static] struct {
@@ -20,7 +22,7 @@ func NewStaticRepository[T Static](db *postgres.DbCluster) StaticRepository[T] {
 
 func (r *staticRepositoryImpl[T]) Create(ctx context.Context, record *T) error {
 	if record == nil {
-		return errors.New("record cannot be nil")
+		return ErrNilRecord
 	}
 	(*record).SetID(uuid.New().String())
 	return r.db.GetMasterDB(ctx).Create(record).Error
@@ -38,7 +40,9 @@ func (r *staticRepositoryImpl[T]) UpdateByCondition(ctx context.Context, filter
 }
 
 func (r *staticRepositoryImpl[T]) UpdatesByConditions(ctx context.Context, filter map[string]interface{}, updates map[string]interface{}) error {
-	return r.db.GetMasterDB(ctx).Where(filter).Updates(updates).Error
+	// A map carries no table, the model tells gorm which one to update
+	var t T
+	return r.db.GetMasterDB(ctx).Model(&t).Where(filter).Updates(updates).Error
 }
 
 func (r *staticRepositoryImpl[T]) Delete(ctx context.Context, record *T) error {
@@ -53,6 +57,9 @@ func (r *staticRepositoryImpl[T]) DeleteByConditions(ctx context.Context, filter
 func (r *staticRepositoryImpl[T]) GetByConditions(ctx context.Context, filter map[string]interface{}) (*T, error) {
 	var result T
 	err := r.db.GetSlaveDB(ctx).Where(filter).First(&result).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
 	return &result, err
 }
 
@@ -61,3 +68,19 @@ func (r *staticRepositoryImpl[T]) GetAllByConditions(ctx context.Context, filter
 	err := r.db.GetSlaveDB(ctx).Where(filter).Find(&results).Error
 	return results, err
 }
+
+func (r *staticRepositoryImpl[T]) GetAllByConditionsPage(ctx context.Context, filter map[string]interface{}, page pagination.Request) (*pagination.Page[T], error) {
+	query, err := pagination.Apply(r.db.GetSlaveDB(ctx).Where(filter), "id", page)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []T
+	if err := query.Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return pagination.NewPage(results, page, func(record T) string {
+		return record.GetID()
+	}), nil
+}
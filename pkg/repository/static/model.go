@@ -4,6 +4,10 @@ type Model struct {
 	ID string `gorm:"column:id;primaryKey:false"`
 }
 
+func (m *Model) GetID() string {
+	return m.ID
+}
+
 func (m *Model) SetID(id string) {
 	m.ID = id
 }
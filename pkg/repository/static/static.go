@@ -1,8 +1,13 @@
 package static
 
-import "context"
+import (
+	"context"
+
+	"github.com/mercor/payment-service/pkg/pagination"
+)
 
 type Static interface {
+	GetID() string
 	SetID(id string)
 }
 
@@ -15,4 +20,5 @@ type StaticRepository[T Static] interface {
 	DeleteByConditions(ctx context.Context, filter map[string]interface{}) error
 	GetByConditions(ctx context.Context, filter map[string]interface{}) (*T, error)
 	GetAllByConditions(ctx context.Context, filter map[string]interface{}) ([]T, error)
+	GetAllByConditionsPage(ctx context.Context, filter map[string]interface{}, page pagination.Request) (*pagination.Page[T], error)
 }
@@ -1,7 +1,11 @@
 package validator
 
 import (
+	"reflect"
+
+	"github.com/gin-gonic/gin/binding"
 	validatorPkg "github.com/go-playground/validator/v10"
+	"github.com/mercor/payment-service/pkg/money"
 )
 
 var validatePkg *validatorPkg.Validate
@@ -12,4 +16,20 @@ func Get() *validatorPkg.Validate {
 
 func Set() {
 	validatePkg = validatorPkg.New()
+	registerCustomTypes(validatePkg)
+
+	// Request binding in gin uses its own validator
+	if ginValidate, ok := binding.Validator.Engine().(*validatorPkg.Validate); ok {
+		registerCustomTypes(ginValidate)
+	}
+}
+
+// registerCustomTypes lets tags such as gt=0 compare the value of custom types
+func registerCustomTypes(validate *validatorPkg.Validate) {
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if d, ok := field.Interface().(money.Decimal); ok {
+			return d.InexactFloat64()
+		}
+		return nil
+	}, money.Decimal{})
 }
@@ -3,45 +3,184 @@ package router
 import (
 	"context"
 
+	"github.com/mercor/payment-service/internal/authorization"
+	"github.com/mercor/payment-service/internal/controller/company"
 	"github.com/mercor/payment-service/internal/controller/contractor"
+	"github.com/mercor/payment-service/internal/controller/invoice"
 	"github.com/mercor/payment-service/internal/controller/job"
 	"github.com/mercor/payment-service/internal/controller/payment"
+	"github.com/mercor/payment-service/internal/controller/payout"
 	"github.com/mercor/payment-service/internal/controller/timelog"
+	middlewares "github.com/mercor/payment-service/internal/middleware"
 	"github.com/mercor/payment-service/pkg/cluster"
 	uhttp "github.com/mercor/payment-service/pkg/http"
 )
 
+// Roles admitted by the route groups, admins are admitted everywhere
+var (
+	allRoles        = []middlewares.Role{middlewares.RoleAdmin, middlewares.RoleCompany, middlewares.RoleContractor}
+	companyRoles    = []middlewares.Role{middlewares.RoleAdmin, middlewares.RoleCompany}
+	contractorRoles = []middlewares.Role{middlewares.RoleAdmin, middlewares.RoleContractor}
+	adminRoles      = []middlewares.Role{middlewares.RoleAdmin}
+)
+
 func PublicRoutes(ctx context.Context, s *uhttp.Server) (err error) {
 	paymentController, _ := payment.Wire(ctx, cluster.GetCluster().DbCluster)
-	timelogController, _ := timelog.Wire(ctx, cluster.GetCluster().DbCluster)
+	timelogController, err := timelog.Wire(ctx, cluster.GetCluster().DbCluster)
+	if err != nil {
+		return err
+	}
 	contractorController, _ := contractor.Wire(ctx, cluster.GetCluster().DbCluster)
+	companyController, _ := company.Wire(ctx, cluster.GetCluster().DbCluster)
 	jobController, _ := job.Wire(ctx, cluster.GetCluster().DbCluster)
+	payoutController, _ := payout.Wire(ctx, cluster.GetCluster().DbCluster)
+	invoiceController, _ := invoice.Wire(ctx, cluster.GetCluster().DbCluster)
+
+	authorizer, err := authorization.Wire(ctx, cluster.GetCluster().DbCluster)
+	if err != nil {
+		return err
+	}
+
+	if err := middlewares.LoadVerifier(ctx); err != nil {
+		return err
+	}
+
+	// Every endpoint needs a valid token, the groups below declare the roles they admit
+	// and the resource their path names has to belong to the user unless it's an admin
+	api := s.Engine.Group("/api/v1", middlewares.AuthenticateJWT(ctx))
+
+	contractorProfile := api.Group("/contractors/:contractor_id", middlewares.RequireRoles(allRoles...), authorizer.Param("contractor_id", authorizer.ContractorProfile))
+	{
+		contractorProfile.GET("", contractorController.GetContractorByID)
+	}
+
+	contractor := api.Group("/contractors/:contractor_id", middlewares.RequireRoles(allRoles...), authorizer.Param("contractor_id", authorizer.Contractor))
+	{
+		contractor.GET("/payment-line-items", paymentController.GetPaymentLineItemsForContractorPeriod)
+		contractor.GET("/earnings", paymentController.GetEarningsForContractorPeriod)
+		contractor.GET("/payouts", payoutController.GetPayoutsForContractor)
+		contractor.GET("/timelogs", timelogController.GetTimelogsForContractorPeriod)
+	}
+
+	contractorSearch := api.Group("/contractors", middlewares.RequireRoles(companyRoles...))
+	{
+		contractorSearch.GET("", contractorController.GetContractors)
+	}
+
+	contractorAccount := api.Group("/contractors/:contractor_id", middlewares.RequireRoles(contractorRoles...), authorizer.Param("contractor_id", authorizer.Contractor))
+	{
+		contractorAccount.PUT("", contractorController.UpdateContractor)
+	}
+
+	contractorAdmin := api.Group("/contractors", middlewares.RequireRoles(adminRoles...))
+	{
+		contractorAdmin.POST("", contractorController.CreateContractor)
+		contractorAdmin.POST("/:contractor_id/deactivate", contractorController.DeactivateContractor)
+		contractorAdmin.POST("/:contractor_id/payouts", payoutController.CreatePayout)
+	}
+
+	companies := api.Group("/companies/:company_id", middlewares.RequireRoles(companyRoles...), authorizer.Param("company_id", authorizer.Company))
+	{
+		companies.GET("", companyController.GetCompanyByID)
+		companies.PUT("", companyController.UpdateCompany)
+		companies.POST("/invoices", invoiceController.DraftInvoice)
+		companies.GET("/invoices", invoiceController.GetInvoicesForCompany)
+	}
+
+	companyAdmin := api.Group("/companies", middlewares.RequireRoles(adminRoles...))
+	{
+		companyAdmin.POST("", companyController.CreateCompany)
+		companyAdmin.GET("", companyController.GetCompanies)
+		companyAdmin.DELETE("/:company_id", companyController.DeleteCompany)
+	}
+
+	// Job listings are narrowed to the jobs of the user by the controller
+	jobs := api.Group("/jobs", middlewares.RequireRoles(allRoles...))
+	{
+		jobs.GET("", jobController.GetJobs)
+		jobs.GET("/extended", jobController.GetExtendedJobs)
+	}
+
+	activeJobs := api.Group("/jobs/active/:contractor_id", middlewares.RequireRoles(allRoles...), authorizer.Param("contractor_id", authorizer.Contractor))
+	{
+		activeJobs.GET("", jobController.GetActiveJobsForContractor)
+	}
+
+	job := api.Group("/jobs/:id", middlewares.RequireRoles(allRoles...), authorizer.Param("id", authorizer.Job))
+	{
+		job.GET("", jobController.GetJobByID)
+		job.GET("/history", jobController.GetJobHistory)
+	}
+
+	// The company of a new job is checked by the controller
+	jobCreation := api.Group("/jobs", middlewares.RequireRoles(companyRoles...))
+	{
+		jobCreation.POST("", jobController.CreateJob)
+	}
+
+	jobManagement := api.Group("/jobs/:id", middlewares.RequireRoles(companyRoles...), authorizer.Param("id", authorizer.Job))
+	{
+		jobManagement.PUT("", jobController.UpdateJob)
+		jobManagement.PATCH("", jobController.PatchJob)
+		jobManagement.POST("/activate", jobController.ActivateJob)
+		jobManagement.POST("/extend", jobController.ExtendJob)
+		jobManagement.POST("/pause", jobController.PauseJob)
+		jobManagement.POST("/end", jobController.EndJob)
+	}
+
+	paymentLineItem := api.Group("/payment-line-items/:id", middlewares.RequireRoles(allRoles...), authorizer.Param("id", authorizer.PaymentLineItem))
+	{
+		paymentLineItem.GET("", paymentController.GetPaymentLineItemByID)
+		paymentLineItem.GET("/history", paymentController.GetPaymentLineItemHistory)
+	}
+
+	paymentLineItemAdmin := api.Group("/payment-line-items", middlewares.RequireRoles(adminRoles...))
+	{
+		paymentLineItemAdmin.PUT("/:id", paymentController.UpdatePaymentLineItemByID)
+		paymentLineItemAdmin.PATCH("/:id", paymentController.PatchPaymentLineItemByID)
+	}
+
+	payoutByID := api.Group("/payouts/:id", middlewares.RequireRoles(contractorRoles...), authorizer.Param("id", authorizer.Payout))
+	{
+		payoutByID.GET("", payoutController.GetPayoutByID)
+	}
+
+	payoutAdmin := api.Group("/payouts", middlewares.RequireRoles(adminRoles...))
+	{
+		payoutAdmin.POST("/:id/settle", payoutController.SettlePayout)
+		payoutAdmin.POST("/:id/cancel", payoutController.CancelPayout)
+	}
 
-	contractor := s.Engine.Group("/api/v1/contractors")
+	invoiceByID := api.Group("/invoices/:id", middlewares.RequireRoles(companyRoles...), authorizer.Param("id", authorizer.Invoice))
 	{
-		contractor.POST("", contractorController.CreateContractor)
+		invoiceByID.GET("", invoiceController.GetInvoiceByID)
+		invoiceByID.POST("/finalize", invoiceController.FinalizeInvoice)
+		invoiceByID.POST("/void", invoiceController.VoidInvoice)
 	}
 
-	job := s.Engine.Group("/api/v1/jobs")
+	timelogByID := api.Group("/timelogs/:id", middlewares.RequireRoles(allRoles...), authorizer.Param("id", authorizer.Timelog))
 	{
-		job.POST("", jobController.CreateJob)
-		job.GET("/extended", jobController.GetJobsByStatus)
-		job.GET("/active/:contractor_id", jobController.GetActiveJobsForContractor)
+		timelogByID.GET("/history", timelogController.GetTimelogHistory)
 	}
 
-	paymentLineItems := s.Engine.Group("/api/v1/payment-line-items")
+	// The jobs time is logged against are checked by the controller
+	timelogLogging := api.Group("/timelogs", middlewares.RequireRoles(contractorRoles...))
 	{
-		paymentLineItems.PUT(":id", paymentController.UpdatePaymentLineItemByID)
+		timelogLogging.POST("", timelogController.CreateTimelog)
+		timelogLogging.POST("/batch", timelogController.CreateTimelogs)
 	}
 
-	payment := s.Engine.Group("/api/v1/contractors/:contractor_id/payment-line-items")
+	timelogReview := api.Group("/timelogs/:id", middlewares.RequireRoles(companyRoles...), authorizer.Param("id", authorizer.Timelog))
 	{
-		payment.GET("", paymentController.GetPaymentLineItemsForContractorPeriod)
+		timelogReview.POST("/approve", timelogController.ApproveTimelog)
+		timelogReview.POST("/reject", timelogController.RejectTimelog)
 	}
 
-	timelog := s.Engine.Group("/api/v1/contractors/:contractor_id/timelogs")
+	admin := api.Group("/admin", middlewares.RequireRoles(adminRoles...))
 	{
-		timelog.GET("", timelogController.GetTimelogsForContractorPeriod)
+		admin.POST("/jobs/:id/revert", jobController.RevertJob)
+		admin.POST("/timelogs/:id/revert", timelogController.RevertTimelog)
+		admin.POST("/payment-line-items/:id/revert", paymentController.RevertPaymentLineItem)
 	}
 
 	return nil
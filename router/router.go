@@ -3,15 +3,12 @@ package router
 import (
 	"context"
 
+	"github.com/mercor/payment-service/pkg/apperror"
 	"github.com/mercor/payment-service/pkg/config"
 	"github.com/mercor/payment-service/pkg/http"
 	"github.com/mercor/payment-service/pkg/log"
 )
 
-const (
-	DefaultPerPageLimit = 100
-)
-
 func Initialize(ctx context.Context, s *http.Server) (err error) {
 	//Middleware for adding config to ctx
 	s.Engine.Use(config.Middleware())
@@ -23,6 +20,9 @@ func Initialize(ctx context.Context, s *http.Server) (err error) {
 		LogResponse: config.GetBool(ctx, "log.response"),
 	}))
 
+	//Middleware for writing the error envelope of failed requests
+	s.Engine.Use(apperror.Middleware())
+
 	err = PublicRoutes(ctx, s)
 	if err != nil {
 		return